@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/config"
+)
+
+// parseConfig builds the configuration from the defaults, the -config file, environment variables and flags, in that order.
+// The arguments following the flags, e.g. a query command, are returned as they are.
+func parseConfig(args []string) (*config.Config, bool, []string, error) {
+	cfg := config.Default()
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "YAML configuration file, environment variables and flags override its settings")
+	printConfig := fs.Bool("print-config", false, "print the effective configuration as YAML and exit")
+	fs.StringVar(&cfg.Transport.Mode, "mode", cfg.Transport.Mode, "transport mode, can be `stdio`, `streamable-http`, `sse` or `http` (streamable-http and sse on the same listener)")
+	fs.StringVar(&cfg.Transport.Host, "host", cfg.Transport.Host, "host for http server")
+	fs.StringVar(&cfg.Transport.Port, "port", cfg.Transport.Port, "port for http server")
+	fs.StringVar(&cfg.Transport.StreamableHTTPPath, "streamable-http-path", cfg.Transport.StreamableHTTPPath, "endpoint path of the streamable-http transport")
+	fs.StringVar(&cfg.Transport.SSEPath, "sse-path", cfg.Transport.SSEPath, "endpoint path of the sse transport")
+	fs.StringVar(&cfg.Auth.TokensFile, "auth-tokens-file", cfg.Auth.TokensFile, "JSON file of static bearer tokens and their tool allowlists for http server")
+	fs.StringVar(&cfg.Auth.JWKSFile, "auth-jwks-file", cfg.Auth.JWKSFile, "JWKS file used to validate JWT access tokens for http server")
+	fs.StringVar(&cfg.Auth.JWKSURL, "auth-jwks-url", cfg.Auth.JWKSURL, "JWKS URL used to validate JWT access tokens for http server")
+	fs.StringVar(&cfg.Auth.Issuer, "auth-issuer", cfg.Auth.Issuer, "expected issuer of JWT access tokens")
+	fs.StringVar(&cfg.Auth.Audience, "auth-audience", cfg.Auth.Audience, "expected audience of JWT access tokens, defaults to the resource URL")
+	fs.Var((*listValue)(&cfg.Auth.RequiredScopes), "auth-required-scopes", "comma separated scopes JWT access tokens must all grant")
+	fs.StringVar(&cfg.Auth.Resource, "auth-resource", cfg.Auth.Resource, "public URL of this server, advertised as the protected resource in OAuth metadata")
+	fs.StringVar(&cfg.Auth.AuthorizationServer, "auth-authorization-server", cfg.Auth.AuthorizationServer, "authorization server issuing JWT access tokens, advertised in OAuth metadata")
+	fs.StringVar(&cfg.Transport.TLSCertFile, "tls-cert", cfg.Transport.TLSCertFile, "TLS certificate file for http server, TLS is enabled when both -tls-cert and -tls-key are set")
+	fs.StringVar(&cfg.Transport.TLSKeyFile, "tls-key", cfg.Transport.TLSKeyFile, "TLS private key file for http server")
+	fs.DurationVar(&cfg.Transport.ReadTimeout, "read-timeout", cfg.Transport.ReadTimeout, "maximum duration for reading an http request")
+	fs.DurationVar(&cfg.Transport.WriteTimeout, "write-timeout", cfg.Transport.WriteTimeout, "maximum duration for writing an http response, 0 means no limit which long-lived SSE streams need")
+	fs.DurationVar(&cfg.Transport.IdleTimeout, "idle-timeout", cfg.Transport.IdleTimeout, "maximum duration an idle keep-alive connection is kept open")
+	fs.DurationVar(&cfg.Transport.ShutdownTimeout, "shutdown-timeout", cfg.Transport.ShutdownTimeout, "maximum duration to drain active sessions on shutdown")
+	fs.StringVar(&cfg.Metrics.Path, "metrics-path", cfg.Metrics.Path, "path of the Prometheus metrics endpoint of the http server, empty to disable it")
+	fs.Var((*listValue)(&cfg.Providers.Prefetch), "prefetch-providers", "comma separated providers to download at startup, e.g. `Azure/azapi@~> 2.0,hashicorp/azurerm`")
+	fs.Var((*listValue)(&cfg.Providers.WorkingDirRoots), "working-dir-roots", "comma separated directories the working_dir of schema queries must be in with the http transports, none is accepted when empty")
+	fs.StringVar(&cfg.Cache.Dir, "cache-dir", cfg.Cache.Dir, "directory providers are downloaded to, defaults to the system temporary directory. It becomes the temporary directory of the process, TMPDIR or TMP on Windows")
+	fs.Var((*listValue)(&cfg.Tools.Groups), "tool-groups", "comma separated tool groups to enable: azapi, terraform-provider")
+	fs.Var((*listValue)(&cfg.Tools.Enabled), "tools", "comma separated tools to enable in addition to -tool-groups, all tools are enabled when both are empty")
+	fs.Var((*listValue)(&cfg.Tools.Disabled), "disable-tools", "comma separated tools to disable even when their group is enabled")
+	fs.Var((*listValue)(&cfg.Prompts.Enabled), "prompts", "comma separated prompts to enable, all prompts are enabled when empty")
+	fs.Var((*listValue)(&cfg.Prompts.Disabled), "disable-prompts", "comma separated prompts to disable")
+	fs.IntVar(&cfg.Output.MaxBytes, "output-max-bytes", cfg.Output.MaxBytes, "maximum size of tool results in bytes, larger results are truncated, 0 turns the limit off")
+	fs.IntVar(&cfg.Output.MaxDepth, "output-max-depth", cfg.Output.MaxDepth, "objects nested deeper than this in AzAPI schemas are collapsed to {...}, 0 turns the limit off")
+	fs.IntVar(&cfg.Output.PageSize, "output-page-size", cfg.Output.PageSize, "number of items per page of long lists such as API versions, 0 turns paging off")
+	fs.StringVar(&cfg.Logging.Level, "log-level", cfg.Logging.Level, "log level, can be `debug`, `info`, `warn` or `error`")
+	fs.StringVar(&cfg.Logging.Format, "log-format", cfg.Logging.Format, "log format, can be `text` or `json`")
+	fs.StringVar(&cfg.Logging.File, "log-file", cfg.Logging.File, "file to write logs to, defaults to stderr in stdio mode and stdout otherwise")
+	fs.BoolVar(&cfg.Logging.ToClient, "log-to-client", cfg.Logging.ToClient, "also send tool call logs to the client as MCP logging notifications")
+	fs.StringVar(&cfg.Tracing.OTLPEndpoint, "otlp-endpoint", cfg.Tracing.OTLPEndpoint, "OTLP/HTTP endpoint traces are exported to, e.g. `http://localhost:4318`, empty to disable tracing")
+	_ = fs.Parse(args)
+
+	// The flags are bound to cfg, remember the explicitly set ones and apply them again over the file and environment.
+	set := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = f.Value.String()
+	})
+	loaded := config.Default()
+	if *configFile != "" {
+		var err error
+		if loaded, err = config.Load(*configFile); err != nil {
+			return nil, false, nil, err
+		}
+	}
+	if err := loaded.ApplyEnv(os.LookupEnv); err != nil {
+		return nil, false, nil, fmt.Errorf("invalid environment variable: %w", err)
+	}
+	*cfg = *loaded
+	for name, value := range set {
+		if err := fs.Set(name, value); err != nil {
+			return nil, false, nil, fmt.Errorf("invalid value %q for flag -%s: %w", value, name, err)
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, false, nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return cfg, *printConfig, fs.Args(), nil
+}
+
+// listValue is a flag holding a comma separated list.
+type listValue []string
+
+func (l *listValue) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listValue) Set(value string) error {
+	*l = config.SplitList(value)
+	return nil
+}
@@ -2,26 +2,87 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"runtime"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/auth"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/azapi"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/cli"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/config"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/httpserver"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/logging"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/metrics"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/tfprovider"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/toolcall"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/tracing"
 	"github.com/matt-FFFFFF/tfpluginschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 func main() {
-	l := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelWarn,
-	}))
+	os.Exit(run())
+}
+
+func run() int {
+	cfg, printConfig, args, err := parseConfig(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 2
+	}
+	if printConfig {
+		if err = cfg.WriteYAML(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 1
+		}
+		return 0
+	}
+	queryMode := len(args) > 0 && args[0] == "query"
+	if len(args) > 0 && !queryMode {
+		fmt.Fprintf(os.Stderr, "unknown command %q, the only command is query\n", args[0])
+		return 2
+	}
+
+	// In stdio mode stdout carries the MCP messages and in query mode the result, anything else written to it gets in the way.
+	var logOutput io.Writer = os.Stdout
+	if cfg.Transport.Mode == config.ModeStdio || queryMode {
+		logOutput = os.Stderr
+	}
+	l, logCloser, err := logging.New(logging.Options{Level: cfg.Logging.Level, Format: cfg.Logging.Format, File: cfg.Logging.File}, logOutput)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 2
+	}
+	defer logCloser.Close()
+	slog.SetDefault(l)
 
-	mode := flag.String("mode", getenv("TRANSPORT_MODE", "stdio"), "transport mode, can be `stdio` or `streamable-http`")
-	host := flag.String("host", getenv("TRANSPORT_HOST", "127.0.0.1"), "host for streamable-http server")
-	port := flag.String("port", getenv("TRANSPORT_PORT", "8080"), "port for streamable-http server")
-	flag.Parse()
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Endpoint:       cfg.Tracing.OTLPEndpoint,
+		ServiceName:    "terraform-mcp-eva",
+		ServiceVersion: "0.1.0",
+	})
+	if err != nil {
+		l.Error(err.Error())
+		return 2
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			l.Warn("failed to flush traces", "error", err)
+		}
+	}()
 
 	server := mcp.NewServer(&mcp.Implementation{
 		Name:    "mcp-ever",
@@ -29,38 +90,245 @@ func main() {
 		Title:   "Terraform provider MCP Server",
 	}, nil)
 
-	pkg.RegisterMcpServer(server)
+	opts := pkg.Options{
+		Groups:          cfg.Tools.Groups,
+		Tools:           cfg.Tools.Enabled,
+		DisabledTools:   cfg.Tools.Disabled,
+		Prompts:         cfg.Prompts.Enabled,
+		DisabledPrompts: cfg.Prompts.Disabled,
+	}
+	err = pkg.RegisterMcpServer(server, opts,
+		tracing.ToolMiddleware,
+		logging.ToolMiddleware(l, cfg.Logging.ToClient),
+		metrics.ToolMiddleware,
+		toolcall.ApplyLimits(toolcall.Limits{
+			MaxBytes: cfg.Output.MaxBytes,
+			MaxDepth: cfg.Output.MaxDepth,
+			PageSize: cfg.Output.PageSize,
+		}),
+	)
+	if err != nil {
+		l.Error(err.Error())
+		return 2
+	}
+	if err := metrics.ObserveSessions(server); err != nil {
+		l.Error(err.Error())
+		return 1
+	}
 
-	providerSchemaServer := tfpluginschema.NewServer(nil)
+	cliConfig, err := tfprovider.LoadCLIConfig()
+	if err != nil {
+		l.Error(err.Error())
+		return 1
+	}
+	if len(cfg.Providers.Mirrors) > 0 {
+		cliConfig.Installation = cfg.Providers.InstallationMethods()
+	}
+	registry := tfprovider.NewRegistry(cliConfig)
+	// tfpluginschema downloads providers with http.DefaultClient, route it through the configured installation methods.
+	// The transport only handles provider downloads, other requests of http.DefaultClient are passed through.
+	http.DefaultClient.Transport = registry.Transport(http.DefaultTransport)
+	if cfg.Cache.Dir != "" {
+		if err = useCacheDir(cfg.Cache.Dir); err != nil {
+			l.Error(err.Error())
+			return 1
+		}
+	}
+	providerSchemaServer := tfprovider.NewSchemaServer(tfpluginschema.NewServer(l.With("component", "tfpluginschema")))
+	prefetch, err := tfprovider.ParsePrefetchList(strings.Join(cfg.Providers.Prefetch, ","))
+	if err != nil {
+		l.Error(err.Error())
+		return 1
+	}
 
-	switch *mode {
-	case "stdio":
-		ctx := context.Background()
-		ctx = context.WithValue(ctx, tfpluginschema.ContextKey{}, providerSchemaServer)
-		if err := server.Run(ctx, mcp.NewStdioTransport()); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// Only the local client of the stdio transport and query mode may read any working directory.
+	workingDirs := tfprovider.WorkingDirs{
+		Any:   queryMode || cfg.Transport.Mode == config.ModeStdio,
+		Roots: cfg.Providers.WorkingDirRoots,
+	}
+	withDependencies := func(ctx context.Context) context.Context {
+		ctx = context.WithValue(ctx, tfprovider.SchemaServerContextKey{}, providerSchemaServer)
+		ctx = context.WithValue(ctx, tfprovider.WorkingDirsContextKey{}, workingDirs)
+		return context.WithValue(ctx, tfprovider.ContextKey{}, registry)
+	}
+
+	if queryMode {
+		defer providerSchemaServer.Cleanup()
+		err = cli.Query(withDependencies(ctx), server, args[1:], os.Stdout, os.Stderr)
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		if errors.Is(err, cli.ErrToolFailed) {
+			return 1
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 2
+		}
+		return 0
+	}
+
+	health := httpserver.NewHealth()
+	indexProperties := opts.ToolEnabled(pkg.GroupAzAPI, "find_azapi_properties") || opts.ToolEnabled(pkg.GroupAzAPI, "search_azapi_properties")
+	warmUp(ctx, l, health, registry, providerSchemaServer, prefetch, indexProperties)
+
+	switch cfg.Transport.Mode {
+	case config.ModeStdio:
+		if err := server.Run(withDependencies(ctx), mcp.NewStdioTransport()); err != nil {
 			l.Error(err.Error())
 		}
-	case "streamable-http":
-		addr := fmt.Sprintf("%s:%s", *host, *port)
-		l.Info("MCP server serving", "address", addr)
-		handler := mcp.NewSSEHandler(func(request *http.Request) *mcp.Server {
-			// Add context with dependencies to the request
-			ctxWithDeps := context.WithValue(request.Context(), tfpluginschema.ContextKey{}, providerSchemaServer)
-			request = request.WithContext(ctxWithDeps)
-			return server
+	case httpserver.ModeStreamableHTTP, httpserver.ModeSSE, httpserver.ModeHTTP:
+		addr := fmt.Sprintf("%s:%s", cfg.Transport.Host, cfg.Transport.Port)
+		l.Info("MCP server serving", "address", addr, "mode", cfg.Transport.Mode)
+		handler, err := httpserver.NewHandler(server, httpserver.Options{
+			Mode:               cfg.Transport.Mode,
+			StreamableHTTPPath: cfg.Transport.StreamableHTTPPath,
+			SSEPath:            cfg.Transport.SSEPath,
+			ContextValues:      withDependencies,
 		})
-		if err := http.ListenAndServe(addr, handler); err != nil {
+		if err != nil {
+			l.Error(err.Error())
+			return 1
+		}
+		handler, err = withAuth(handler, server, cfg.Auth)
+		if err != nil {
+			l.Error(err.Error())
+			return 1
+		}
+		mux := http.NewServeMux()
+		health.Register(mux)
+		if cfg.Metrics.Path != "" {
+			mux.Handle(cfg.Metrics.Path, metrics.Handler())
+		}
+		mux.Handle("/", handler)
+		httpServer, err := httpserver.NewServer(httpserver.Config{
+			Addr:            addr,
+			TLSCertFile:     cfg.Transport.TLSCertFile,
+			TLSKeyFile:      cfg.Transport.TLSKeyFile,
+			ReadTimeout:     cfg.Transport.ReadTimeout,
+			WriteTimeout:    cfg.Transport.WriteTimeout,
+			IdleTimeout:     cfg.Transport.IdleTimeout,
+			ShutdownTimeout: cfg.Transport.ShutdownTimeout,
+		}, mux, server, health)
+		if err != nil {
+			l.Error(err.Error())
+			return 1
+		}
+		if err := httpServer.ListenAndServe(ctx); err != nil {
 			l.Error(err.Error())
 		}
 	default:
-		l.Error("unknown mode", "mode", *mode)
-		os.Exit(1)
+		l.Error("unknown mode", "mode", cfg.Transport.Mode)
+		return 1
+	}
+	return 0
+}
+
+// useCacheDir makes tfpluginschema download providers to dir, it creates its download directory in os.TempDir.
+// tfpluginschema has no option for the directory, so dir is the temporary directory of the whole process.
+func useCacheDir(dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory %s: %w", dir, err)
+	}
+	variable := "TMPDIR"
+	if runtime.GOOS == "windows" {
+		variable = "TMP"
+	}
+	return os.Setenv(variable, dir)
+}
+
+// warmUp loads the AzAPI type index, indexes the AzAPI properties when indexProperties is set and prefetches providers in the background,
+// the server reports ready once all are done.
+func warmUp(ctx context.Context, l *slog.Logger, health *httpserver.Health, registry *tfprovider.Registry, server *tfprovider.SchemaServer, prefetch []tfprovider.ResolveRequest, indexProperties bool) {
+	azapiTypesLoaded := health.Track("azapi-types")
+	var propertiesIndexed func(error)
+	if indexProperties {
+		propertiesIndexed = health.Track("azapi-property-index")
+	}
+	go func() {
+		err := azapi.LoadSchemaIndex()
+		if err != nil {
+			l.Error(err.Error())
+		}
+		azapiTypesLoaded(err)
+		if propertiesIndexed == nil {
+			return
+		}
+		if err == nil {
+			err = azapi.LoadPropertyIndex(logging.WithLogger(ctx, l))
+			if err != nil {
+				l.Error(err.Error())
+			}
+		}
+		propertiesIndexed(err)
+	}()
+	if len(prefetch) == 0 {
+		return
 	}
+	providersFetched := health.Track("provider-schemas")
+	go func() {
+		err := tfprovider.Prefetch(ctx, registry, server, prefetch)
+		if err != nil {
+			l.Error(err.Error())
+		}
+		providersFetched(err)
+	}()
 }
 
-func getenv(key, fallback string) string {
-	if value, ok := os.LookupEnv(key); ok {
-		return value
+// withAuth protects handler with bearer token authentication when static tokens or a JWKS are configured,
+// and enforces the tool allowlists of the authenticated principals on the MCP server.
+func withAuth(handler http.Handler, server *mcp.Server, settings config.Auth) (http.Handler, error) {
+	var authenticators []auth.Authenticator
+	tokens := slices.Clone(settings.Tokens)
+	if settings.TokensFile != "" {
+		fromFile, err := auth.LoadTokensFile(settings.TokensFile)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, fromFile...)
+	}
+	if len(tokens) > 0 {
+		static, err := auth.NewStaticTokens(tokens)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, static)
+	}
+	var resourceMetadataURL string
+	if settings.JWKSFile != "" || settings.JWKSURL != "" {
+		audience := settings.Audience
+		if audience == "" {
+			audience = settings.Resource
+		}
+		validator, err := auth.NewJWTValidator(auth.JWTOptions{
+			JWKSFile:       settings.JWKSFile,
+			JWKSURL:        settings.JWKSURL,
+			Issuer:         settings.Issuer,
+			Audience:       audience,
+			RequiredScopes: settings.RequiredScopes,
+		})
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, validator)
+		if settings.Resource != "" {
+			resourceMetadataURL = strings.TrimSuffix(settings.Resource, "/") + auth.ResourceMetadataPath
+		}
+	}
+	if len(authenticators) == 0 {
+		return handler, nil
+	}
+	server.AddReceivingMiddleware(auth.ToolAllowlist())
+	mux := http.NewServeMux()
+	mux.Handle("/", auth.NewMiddleware(resourceMetadataURL, authenticators...).Wrap(handler))
+	if resourceMetadataURL != "" {
+		md := auth.ResourceMetadata{Resource: settings.Resource, ScopesSupported: settings.RequiredScopes}
+		if settings.AuthorizationServer != "" {
+			md.AuthorizationServers = []string{settings.AuthorizationServer}
+		}
+		mux.Handle(auth.ResourceMetadataPath, auth.ResourceMetadataHandler(md))
 	}
-	return fallback
+	return mux, nil
 }
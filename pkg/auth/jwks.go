@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// jwk is a single JSON Web Key, only the members needed for signature verification keys are decoded.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+// parseJWKS parses a JSON Web Key Set into public keys indexed by key ID.
+// Keys not meant for signatures and key types we can't verify with are skipped.
+func parseJWKS(content []byte) (map[string]crypto.PublicKey, error) {
+	var set jwkSet
+	if err := json.Unmarshal(content, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey)
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q in JWKS: %w", k.Kid, err)
+		}
+		if key != nil {
+			keys[k.Kid] = key
+		}
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key material: %w", err)
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, nil
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key material: %w", err)
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// keySource provides the verification keys of a JWT issuer, loaded from a file or fetched from a URL.
+type keySource struct {
+	file   string
+	url    string
+	client *http.Client
+	// minRefresh throttles refetching the URL when tokens reference unknown key IDs.
+	minRefresh time.Duration
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+// key returns the key with the given ID, reloading the key set once if the ID is unknown, to pick up rotated keys.
+func (s *keySource) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if k, ok := s.lookup(kid); ok {
+		return k, nil
+	}
+	if s.keys != nil && time.Since(s.fetchedAt) < s.minRefresh {
+		return nil, fmt.Errorf("unknown key ID %q", kid)
+	}
+	if err := s.load(ctx); err != nil {
+		return nil, err
+	}
+	if k, ok := s.lookup(kid); ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("unknown key ID %q", kid)
+}
+
+// lookup finds a key by ID, a token without a key ID is accepted when the set holds a single key.
+func (s *keySource) lookup(kid string) (crypto.PublicKey, bool) {
+	if k, ok := s.keys[kid]; ok {
+		return k, true
+	}
+	if kid == "" && len(s.keys) == 1 {
+		for _, k := range s.keys {
+			return k, true
+		}
+	}
+	return nil, false
+}
+
+func (s *keySource) load(ctx context.Context) error {
+	var content []byte
+	var err error
+	if s.file != "" {
+		if content, err = os.ReadFile(s.file); err != nil {
+			return fmt.Errorf("failed to read JWKS file %s: %w", s.file, err)
+		}
+	} else {
+		if content, err = s.fetch(ctx); err != nil {
+			return err
+		}
+	}
+	keys, err := parseJWKS(content)
+	if err != nil {
+		return err
+	}
+	s.keys = keys
+	s.fetchedAt = time.Now()
+	return nil
+}
+
+func (s *keySource) fetch(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JWKS request: %w", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS from %s: %w", s.url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS from %s => %d", s.url, resp.StatusCode)
+	}
+	var raw json.RawMessage
+	if err = json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to read JWKS from %s: %w", s.url, err)
+	}
+	return raw, nil
+}
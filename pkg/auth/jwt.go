@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ToolScopePrefix marks the scopes of an access token that grant access to a single tool, e.g. `tool:list_azapi_api_versions`.
+// A token without any such scope may call every tool.
+const ToolScopePrefix = "tool:"
+
+// JWTOptions configures OAuth2 resource server mode.
+type JWTOptions struct {
+	// JWKSFile or JWKSURL locate the authorization server's signing keys, exactly one must be set.
+	JWKSFile string
+	JWKSURL  string
+	// Issuer is the expected `iss` claim, not checked when empty.
+	Issuer string
+	// Audience is the expected `aud` claim, normally this server's resource URL, not checked when empty.
+	Audience string
+	// RequiredScopes must all be granted by the `scope` claim.
+	RequiredScopes []string
+	// HTTPClient fetches JWKSURL, a client of its own when nil.
+	HTTPClient *http.Client
+}
+
+// JWTValidator authenticates requests carrying JWT access tokens issued by an OAuth2 authorization server.
+type JWTValidator struct {
+	opts   JWTOptions
+	keys   *keySource
+	parser *jwt.Parser
+}
+
+// NewJWTValidator creates a JWTValidator, the signing keys are loaded lazily on the first request.
+func NewJWTValidator(opts JWTOptions) (*JWTValidator, error) {
+	if (opts.JWKSFile == "") == (opts.JWKSURL == "") {
+		return nil, fmt.Errorf("exactly one of JWKS file and JWKS URL must be set")
+	}
+	client := opts.HTTPClient
+	if client == nil {
+		// Not http.DefaultClient, its transport is replaced to install providers.
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if opts.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(opts.Issuer))
+	}
+	if opts.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(opts.Audience))
+	}
+	return &JWTValidator{
+		opts: opts,
+		keys: &keySource{
+			file:       opts.JWKSFile,
+			url:        opts.JWKSURL,
+			client:     client,
+			minRefresh: time.Minute,
+		},
+		parser: jwt.NewParser(parserOpts...),
+	}, nil
+}
+
+type accessTokenClaims struct {
+	jwt.RegisteredClaims
+	Scope string `json:"scope"`
+}
+
+func (v *JWTValidator) Authenticate(r *http.Request) (*Principal, error) {
+	token, ok := bearerToken(r)
+	if !ok {
+		return nil, ErrMissingToken
+	}
+	var claims accessTokenClaims
+	_, err := v.parser.ParseWithClaims(token, &claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.key(r.Context(), kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+	scopes := strings.Fields(claims.Scope)
+	for _, required := range v.opts.RequiredScopes {
+		if !slices.Contains(scopes, required) {
+			return nil, fmt.Errorf("%w: missing scope %q", ErrInvalidToken, required)
+		}
+	}
+	principal := &Principal{Subject: claims.Subject}
+	for _, s := range scopes {
+		if tool, ok := strings.CutPrefix(s, ToolScopePrefix); ok {
+			principal.Tools = append(principal.Tools, tool)
+		}
+	}
+	return principal, nil
+}
@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIssuer   = "https://login.example.com/"
+	testAudience = "https://mcp.example.com"
+)
+
+type testSigner struct {
+	kid string
+	key *rsa.PrivateKey
+}
+
+func newTestSigner(t *testing.T, kid string) *testSigner {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return &testSigner{kid: kid, key: key}
+}
+
+func (s *testSigner) jwks(t *testing.T) []byte {
+	t.Helper()
+	content, err := json.Marshal(map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": s.kid,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(s.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(s.key.E)).Bytes()),
+		}},
+	})
+	require.NoError(t, err)
+	return content
+}
+
+func (s *testSigner) token(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = s.kid
+	signed, err := token.SignedString(s.key)
+	require.NoError(t, err)
+	return signed
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":   testIssuer,
+		"aud":   testAudience,
+		"sub":   "alice",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": "mcp tool:list_azapi_api_versions",
+	}
+}
+
+func TestJWTValidator_JWKSFile(t *testing.T) {
+	signer := newTestSigner(t, "key-1")
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, signer.jwks(t), 0600))
+	v, err := NewJWTValidator(JWTOptions{JWKSFile: path, Issuer: testIssuer, Audience: testAudience, RequiredScopes: []string{"mcp"}})
+	require.NoError(t, err)
+
+	p, err := v.Authenticate(requestWithToken(signer.token(t, validClaims())))
+	require.NoError(t, err)
+	assert.Equal(t, &Principal{Subject: "alice", Tools: []string{"list_azapi_api_versions"}}, p)
+
+	cases := map[string]func(jwt.MapClaims){
+		"expired":        func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+		"no expiry":      func(c jwt.MapClaims) { delete(c, "exp") },
+		"wrong issuer":   func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com/" },
+		"wrong audience": func(c jwt.MapClaims) { c["aud"] = "https://other.example.com" },
+		"missing scope":  func(c jwt.MapClaims) { c["scope"] = "tool:list_azapi_api_versions" },
+	}
+	for name, mutate := range cases {
+		t.Run(name, func(t *testing.T) {
+			claims := validClaims()
+			mutate(claims)
+			_, err := v.Authenticate(requestWithToken(signer.token(t, claims)))
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+
+	t.Run("unknown signer", func(t *testing.T) {
+		_, err := v.Authenticate(requestWithToken(newTestSigner(t, "key-2").token(t, validClaims())))
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
+
+func TestJWTValidator_JWKSURL_KeyRotation(t *testing.T) {
+	current := newTestSigner(t, "key-1")
+	var served atomic.Pointer[testSigner]
+	served.Store(current)
+	var fetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		_, _ = w.Write(served.Load().jwks(t))
+	}))
+	t.Cleanup(srv.Close)
+
+	v, err := NewJWTValidator(JWTOptions{JWKSURL: srv.URL, Audience: testAudience})
+	require.NoError(t, err)
+	claims := validClaims()
+	delete(claims, "scope")
+	p, err := v.Authenticate(requestWithToken(current.token(t, claims)))
+	require.NoError(t, err)
+	assert.Nil(t, p.Tools, "a token without tool scopes may call every tool")
+
+	rotated := newTestSigner(t, "key-2")
+	served.Store(rotated)
+	v.keys.minRefresh = 0
+	_, err = v.Authenticate(requestWithToken(rotated.token(t, claims)))
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), fetches.Load())
+}
+
+func TestNewJWTValidator_RequiresExactlyOneKeySource(t *testing.T) {
+	_, err := NewJWTValidator(JWTOptions{})
+	require.Error(t, err)
+	_, err = NewJWTValidator(JWTOptions{JWKSFile: "a", JWKSURL: "b"})
+	require.Error(t, err)
+}
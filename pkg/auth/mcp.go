@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ToolAllowlist returns an MCP server middleware enforcing the tool allowlist of the principal that opened the session.
+// Tools the principal may not call are hidden from `tools/list` and rejected by `tools/call`.
+func ToolAllowlist() mcp.Middleware[*mcp.ServerSession] {
+	return func(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+		return func(ctx context.Context, ss *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+			principal := PrincipalFromContext(ctx)
+			if principal == nil || principal.Tools == nil {
+				return next(ctx, ss, method, params)
+			}
+			switch method {
+			case "tools/call":
+				if p, ok := params.(*mcp.CallToolParamsFor[json.RawMessage]); ok && !principal.AllowsTool(p.Name) {
+					return nil, fmt.Errorf("tool %s is not allowed for %s", p.Name, principal.Subject)
+				}
+			case "tools/list":
+				result, err := next(ctx, ss, method, params)
+				if err != nil {
+					return nil, err
+				}
+				if list, ok := result.(*mcp.ListToolsResult); ok {
+					allowed := make([]*mcp.Tool, 0, len(list.Tools))
+					for _, t := range list.Tools {
+						if principal.AllowsTool(t.Name) {
+							allowed = append(allowed, t)
+						}
+					}
+					list.Tools = allowed
+				}
+				return result, nil
+			}
+			return next(ctx, ss, method, params)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// ResourceMetadataPath is where OAuth 2.0 protected resource metadata (RFC 9728) is served, as required by the MCP authorization spec.
+const ResourceMetadataPath = "/.well-known/oauth-protected-resource"
+
+// ResourceMetadata is the OAuth 2.0 protected resource metadata document.
+type ResourceMetadata struct {
+	Resource               string   `json:"resource"`
+	AuthorizationServers   []string `json:"authorization_servers,omitempty"`
+	ScopesSupported        []string `json:"scopes_supported,omitempty"`
+	BearerMethodsSupported []string `json:"bearer_methods_supported,omitempty"`
+}
+
+// ResourceMetadataHandler serves the protected resource metadata document.
+func ResourceMetadataHandler(md ResourceMetadata) http.Handler {
+	if md.BearerMethodsSupported == nil {
+		md.BearerMethodsSupported = []string{"header"}
+	}
+	body, _ := json.Marshal(md)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	})
+}
+
+const sessionIDHeader = "Mcp-Session-Id"
+
+// sseSessionID extracts the session ID from the endpoint event the SSE transport sends first.
+var sseSessionID = regexp.MustCompile(`sessionid=([A-Za-z0-9_-]+)`)
+
+// DefaultSessionIdleTimeout is how long a session without requests stays bound to its principal.
+const DefaultSessionIdleTimeout = time.Hour
+
+// Middleware authenticates every request with the first authenticator that accepts its bearer token,
+// and stores the resulting Principal in the request context.
+// MCP sessions are bound to the principal that opened them, so a session ID can't be reused with another token.
+// Requests with a session ID the middleware doesn't know, or no longer knows, are answered with 404 Not Found,
+// which tells MCP clients to start a new session.
+type Middleware struct {
+	// SessionIdleTimeout is how long a session without requests is kept, DefaultSessionIdleTimeout when zero.
+	SessionIdleTimeout time.Duration
+
+	authenticators      []Authenticator
+	resourceMetadataURL string
+	now                 func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+// session is an MCP session bound to a principal.
+type session struct {
+	subject  string
+	lastSeen time.Time
+	// active counts the requests of the session being served, e.g. an open SSE stream.
+	active int
+}
+
+// NewMiddleware creates a Middleware. resourceMetadataURL is advertised to unauthenticated clients when not empty.
+func NewMiddleware(resourceMetadataURL string, authenticators ...Authenticator) *Middleware {
+	return &Middleware{
+		authenticators:      authenticators,
+		resourceMetadataURL: resourceMetadataURL,
+		now:                 time.Now,
+		sessions:            make(map[string]*session),
+	}
+}
+
+// Wrap returns a handler that authenticates requests before passing them to next.
+func (m *Middleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := m.authenticate(r)
+		if err != nil {
+			m.challenge(w, err)
+			return
+		}
+		sessionID := r.Header.Get(sessionIDHeader)
+		if sessionID == "" {
+			sessionID = r.URL.Query().Get("sessionid")
+		}
+		if sessionID != "" {
+			if status := m.acquire(sessionID, principal.Subject, r.Method == http.MethodDelete); status != http.StatusOK {
+				http.Error(w, http.StatusText(status), status)
+				return
+			}
+			defer m.release(sessionID)
+		}
+		r = r.WithContext(WithPrincipal(r.Context(), principal))
+		rec := &sessionRecorder{ResponseWriter: w, m: m, subject: principal.Subject}
+		next.ServeHTTP(rec, r)
+		if rec.sseSession != "" {
+			// The SSE session ends with the stream that opened it.
+			m.forget(rec.sseSession)
+		}
+	})
+}
+
+// acquire checks that sessionID belongs to subject and marks one of its requests as being served.
+// It returns the HTTP status to answer with when the request can't be served.
+func (m *Middleware) acquire(sessionID, subject string, closing bool) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[sessionID]
+	switch {
+	case !ok:
+		return http.StatusNotFound
+	case s.subject != subject:
+		return http.StatusForbidden
+	}
+	if closing {
+		delete(m.sessions, sessionID)
+		return http.StatusOK
+	}
+	s.active++
+	s.lastSeen = m.now()
+	return http.StatusOK
+}
+
+func (m *Middleware) release(sessionID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.sessions[sessionID]; ok {
+		s.active--
+		s.lastSeen = m.now()
+	}
+}
+
+func (m *Middleware) forget(sessionID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, sessionID)
+}
+
+func (m *Middleware) authenticate(r *http.Request) (*Principal, error) {
+	var errs []error
+	for _, a := range m.authenticators {
+		p, err := a.Authenticate(r)
+		if err == nil {
+			return p, nil
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return nil, ErrMissingToken
+	}
+	return nil, errors.Join(errs...)
+}
+
+// challenge answers an unauthenticated request as described by RFC 6750 and the MCP authorization spec.
+func (m *Middleware) challenge(w http.ResponseWriter, err error) {
+	challenge := `Bearer realm="mcp"`
+	if m.resourceMetadataURL != "" {
+		challenge += fmt.Sprintf(`, resource_metadata="%s"`, m.resourceMetadataURL)
+	}
+	if errors.Is(err, ErrInvalidToken) {
+		challenge += `, error="invalid_token"`
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+	http.Error(w, "unauthorized", http.StatusUnauthorized)
+}
+
+// bind binds a new session to subject, streaming when the request that opened it serves its stream.
+func (m *Middleware) bind(sessionID, subject string, streaming bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expire()
+	if _, ok := m.sessions[sessionID]; !ok {
+		s := &session{subject: subject, lastSeen: m.now()}
+		if streaming {
+			s.active = 1
+		}
+		m.sessions[sessionID] = s
+	}
+}
+
+// expire forgets the sessions idle for longer than SessionIdleTimeout, m.mu must be held.
+func (m *Middleware) expire() {
+	timeout := m.SessionIdleTimeout
+	if timeout <= 0 {
+		timeout = DefaultSessionIdleTimeout
+	}
+	now := m.now()
+	for id, s := range m.sessions {
+		if s.active == 0 && now.Sub(s.lastSeen) > timeout {
+			delete(m.sessions, id)
+		}
+	}
+}
+
+// sessionRecorder watches responses for newly created session IDs,
+// from the streamable transport's session header or the SSE transport's endpoint event.
+type sessionRecorder struct {
+	http.ResponseWriter
+	m       *Middleware
+	subject string
+	done    bool
+	// sseSession is the session opened by the SSE stream being served.
+	sseSession string
+}
+
+func (s *sessionRecorder) WriteHeader(status int) {
+	s.record(nil)
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *sessionRecorder) Write(p []byte) (int, error) {
+	s.record(p)
+	return s.ResponseWriter.Write(p)
+}
+
+func (s *sessionRecorder) record(p []byte) {
+	if s.done {
+		return
+	}
+	if id := s.Header().Get(sessionIDHeader); id != "" {
+		s.m.bind(id, s.subject, false)
+		s.done = true
+		return
+	}
+	if bytes.Contains(p, []byte("event: endpoint")) {
+		if match := sseSessionID.FindSubmatch(p); match != nil {
+			s.sseSession = string(match[1])
+			s.m.bind(s.sseSession, s.subject, true)
+			s.done = true
+		}
+	}
+}
+
+func (s *sessionRecorder) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (s *sessionRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
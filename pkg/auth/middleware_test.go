@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type echoParam struct {
+	Text string `json:"text"`
+}
+
+func echo(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[echoParam]) (*mcp.CallToolResultFor[any], error) {
+	return &mcp.CallToolResultFor[any]{
+		Content: []mcp.Content{&mcp.TextContent{Text: PrincipalFromContext(ctx).Subject + ":" + params.Arguments.Text}},
+	}, nil
+}
+
+func newProtectedServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "echo"}, echo)
+	mcp.AddTool(server, &mcp.Tool{Name: "echo_admin"}, echo)
+	server.AddReceivingMiddleware(ToolAllowlist())
+	static, err := NewStaticTokens([]StaticToken{
+		{Token: "limited", Subject: "limited", Tools: []string{"echo"}},
+		{Token: "admin", Subject: "admin"},
+	})
+	require.NoError(t, err)
+	handler := mcp.NewSSEHandler(func(*http.Request) *mcp.Server { return server })
+	srv := httptest.NewServer(NewMiddleware("https://mcp.example.com"+ResourceMetadataPath, static).Wrap(handler))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+type tokenTransport struct {
+	token string
+}
+
+func (t tokenTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("Authorization", "Bearer "+t.token)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func connect(t *testing.T, url, token string) *mcp.ClientSession {
+	t.Helper()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil)
+	session, err := client.Connect(context.Background(), mcp.NewSSEClientTransport(url, &mcp.SSEClientTransportOptions{
+		HTTPClient: &http.Client{Transport: tokenTransport{token: token}},
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = session.Close() })
+	return session
+}
+
+func TestMiddleware_Unauthenticated(t *testing.T) {
+	srv := newProtectedServer(t)
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, `Bearer realm="mcp", resource_metadata="https://mcp.example.com/.well-known/oauth-protected-resource"`, resp.Header.Get("WWW-Authenticate"))
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer wrong")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("WWW-Authenticate"), `error="invalid_token"`)
+}
+
+func TestMiddleware_ToolAllowlist(t *testing.T) {
+	srv := newProtectedServer(t)
+	ctx := context.Background()
+
+	limited := connect(t, srv.URL, "limited")
+	tools, err := limited.ListTools(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, tools.Tools, 1)
+	assert.Equal(t, "echo", tools.Tools[0].Name)
+	result, err := limited.CallTool(ctx, &mcp.CallToolParams{Name: "echo", Arguments: map[string]any{"text": "hi"}})
+	require.NoError(t, err)
+	assert.Equal(t, "limited:hi", result.Content[0].(*mcp.TextContent).Text)
+	_, err = limited.CallTool(ctx, &mcp.CallToolParams{Name: "echo_admin", Arguments: map[string]any{"text": "hi"}})
+	require.Error(t, err)
+
+	admin := connect(t, srv.URL, "admin")
+	tools, err = admin.ListTools(ctx, nil)
+	require.NoError(t, err)
+	assert.Len(t, tools.Tools, 2)
+	_, err = admin.CallTool(ctx, &mcp.CallToolParams{Name: "echo_admin", Arguments: map[string]any{"text": "hi"}})
+	require.NoError(t, err)
+}
+
+func TestMiddleware_SessionBoundToPrincipal(t *testing.T) {
+	m := NewMiddleware("")
+	m.bind("session-1", "alice", false)
+	static, err := NewStaticTokens([]StaticToken{{Token: "bob", Subject: "bob"}})
+	require.NoError(t, err)
+	m.authenticators = []Authenticator{static}
+	handler := m.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	r := requestWithToken("bob")
+	r.Header.Set(sessionIDHeader, "session-1")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestMiddleware_UnknownSession(t *testing.T) {
+	static, err := NewStaticTokens([]StaticToken{{Token: "bob", Subject: "bob"}})
+	require.NoError(t, err)
+	handler := NewMiddleware("", static).Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	r := requestWithToken("bob")
+	r.Header.Set(sessionIDHeader, "session-1")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestMiddleware_ForgetsIdleSessions(t *testing.T) {
+	now := time.Now()
+	m := NewMiddleware("")
+	m.SessionIdleTimeout = time.Minute
+	m.now = func() time.Time { return now }
+	m.bind("idle", "alice", false)
+	m.bind("streaming", "alice", true)
+
+	now = now.Add(2 * time.Minute)
+	m.bind("new", "alice", false)
+	assert.NotContains(t, m.sessions, "idle")
+	assert.Contains(t, m.sessions, "streaming")
+	assert.Contains(t, m.sessions, "new")
+}
+
+func TestMiddleware_ForgetsClosedSSESessions(t *testing.T) {
+	static, err := NewStaticTokens([]StaticToken{{Token: "alice", Subject: "alice"}})
+	require.NoError(t, err)
+	m := NewMiddleware("", static)
+	handler := m.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("event: endpoint\ndata: /sse?sessionid=session-1\n\n"))
+		assert.Contains(t, m.sessions, "session-1")
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), requestWithToken("alice"))
+	assert.NotContains(t, m.sessions, "session-1")
+}
+
+func TestResourceMetadataHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	ResourceMetadataHandler(ResourceMetadata{
+		Resource:             "https://mcp.example.com",
+		AuthorizationServers: []string{"https://login.example.com/"},
+	}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, ResourceMetadataPath, nil))
+	assert.JSONEq(t, `{"resource":"https://mcp.example.com","authorization_servers":["https://login.example.com/"],"bearer_methods_supported":["header"]}`, w.Body.String())
+}
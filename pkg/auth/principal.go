@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+)
+
+var (
+	// ErrMissingToken is returned when a request carries no bearer token.
+	ErrMissingToken = errors.New("missing bearer token")
+	// ErrInvalidToken is returned when a bearer token is unknown, malformed or fails validation.
+	ErrInvalidToken = errors.New("invalid bearer token")
+)
+
+// Principal is the identity a request was authenticated as.
+type Principal struct {
+	// Subject identifies the caller, e.g. the name of a static token or the `sub` claim of a JWT.
+	Subject string
+	// Tools is the allowlist of tools the principal may see and call, nil allows every tool.
+	Tools []string
+}
+
+// AllowsTool reports whether the principal may call the named tool.
+func (p *Principal) AllowsTool(name string) bool {
+	return p == nil || p.Tools == nil || slices.Contains(p.Tools, name)
+}
+
+// Authenticator authenticates an HTTP request from its bearer token.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+type principalContextKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, p)
+}
+
+// PrincipalFromContext returns the principal stored in ctx, or nil when the request wasn't authenticated.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalContextKey{}).(*Principal)
+	return p
+}
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// StaticToken is a pre-shared bearer token.
+type StaticToken struct {
+	Token   string   `json:"token" yaml:"token"`
+	Subject string   `json:"subject" yaml:"subject,omitempty"`
+	Tools   []string `json:"tools,omitempty" yaml:"tools,omitempty"`
+}
+
+// StaticTokens authenticates requests against a fixed set of pre-shared tokens.
+type StaticTokens struct {
+	tokens []StaticToken
+}
+
+// NewStaticTokens creates a StaticTokens authenticator, tokens without a subject are named after their position.
+func NewStaticTokens(tokens []StaticToken) (*StaticTokens, error) {
+	result := &StaticTokens{}
+	for i, t := range tokens {
+		if t.Token == "" {
+			return nil, fmt.Errorf("static token #%d is empty", i)
+		}
+		if t.Subject == "" {
+			t.Subject = fmt.Sprintf("token-%d", i)
+		}
+		result.tokens = append(result.tokens, t)
+	}
+	return result, nil
+}
+
+// LoadTokensFile reads static tokens from a JSON file holding an array of StaticToken objects:
+//
+//	[{"token": "...", "subject": "ci", "tools": ["list_azapi_api_versions"]}]
+func LoadTokensFile(path string) ([]StaticToken, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tokens file %s: %w", path, err)
+	}
+	var tokens []StaticToken
+	if err = json.Unmarshal(content, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse tokens file %s: %w", path, err)
+	}
+	return tokens, nil
+}
+
+// ParseTokens parses a comma separated list of tokens, as passed through an environment variable.
+// Such tokens may call every tool.
+func ParseTokens(value string) []StaticToken {
+	var tokens []StaticToken
+	for _, t := range strings.Split(value, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tokens = append(tokens, StaticToken{Token: t})
+		}
+	}
+	return tokens
+}
+
+func (s *StaticTokens) Authenticate(r *http.Request) (*Principal, error) {
+	token, ok := bearerToken(r)
+	if !ok {
+		return nil, ErrMissingToken
+	}
+	for _, t := range s.tokens {
+		if subtle.ConstantTimeCompare([]byte(t.Token), []byte(token)) == 1 {
+			return &Principal{Subject: t.Subject, Tools: t.Tools}, nil
+		}
+	}
+	return nil, ErrInvalidToken
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func requestWithToken(token string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	return r
+}
+
+func TestStaticTokens_Authenticate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[
+  {"token": "ci-token", "subject": "ci", "tools": ["list_azapi_api_versions"]},
+  {"token": "admin-token"}
+]`), 0600))
+	tokens, err := LoadTokensFile(path)
+	require.NoError(t, err)
+	static, err := NewStaticTokens(append(tokens, ParseTokens(" env-token ,")...))
+	require.NoError(t, err)
+
+	p, err := static.Authenticate(requestWithToken("ci-token"))
+	require.NoError(t, err)
+	assert.Equal(t, &Principal{Subject: "ci", Tools: []string{"list_azapi_api_versions"}}, p)
+	assert.True(t, p.AllowsTool("list_azapi_api_versions"))
+	assert.False(t, p.AllowsTool("query_terraform_provider_schema"))
+
+	p, err = static.Authenticate(requestWithToken("admin-token"))
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", p.Subject)
+	assert.True(t, p.AllowsTool("query_terraform_provider_schema"))
+
+	_, err = static.Authenticate(requestWithToken("env-token"))
+	require.NoError(t, err)
+
+	_, err = static.Authenticate(requestWithToken("wrong"))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = static.Authenticate(requestWithToken(""))
+	assert.ErrorIs(t, err, ErrMissingToken)
+}
+
+func TestNewStaticTokens_EmptyToken(t *testing.T) {
+	_, err := NewStaticTokens([]StaticToken{{Subject: "nobody"}})
+	require.Error(t, err)
+}
@@ -0,0 +1,161 @@
+package azapi
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	azapi_resource "github.com/lonegunmanb/terraform-azapi-schema/v2/generated"
+	"github.com/ms-henglu/go-azure-types/types"
+)
+
+// The AzAPI provider blocks the resource schemas are merged into.
+const (
+	BlockResource          = "azapi_resource"
+	BlockUpdateResource    = "azapi_update_resource"
+	BlockDataPlaneResource = "azapi_data_plane_resource"
+	BlockDataResource      = "data.azapi_resource"
+	BlockResourceAction    = "azapi_resource_action"
+)
+
+// BlockTypes are the block types a resource schema can be queried for.
+var BlockTypes = []string{BlockResource, BlockUpdateResource, BlockDataPlaneResource, BlockDataResource, BlockResourceAction}
+
+// Target is the AzAPI block a resource schema is queried for.
+type Target struct {
+	// Block is the block type, e.g. azapi_update_resource or data.azapi_resource, it defaults to azapi_resource.
+	Block        string
+	ResourceType string
+	ApiVersion   string
+	// Action is the action of an azapi_resource_action block, e.g. listKeys.
+	Action string
+	// Schema replaces the embedded provider schema of the block, e.g. with the schema of the provider version a module uses.
+	Schema *tfjson.SchemaBlock
+}
+
+// block is the schema of a target: the provider schema of the block, with the types of its body and output.
+type block struct {
+	schema *tfjson.SchemaBlock
+	// body is the type of the body attribute, nil when the block has none.
+	body types.TypeBase
+	// output is the type of the output attribute, nil when it's left dynamic.
+	output types.TypeBase
+	// rootAttributes are the body properties the block has as top-level attributes.
+	rootAttributes []string
+	// optional blocks only set the properties they change, so no property is required.
+	optional bool
+}
+
+func getBlock(ctx context.Context, target Target) (*block, error) {
+	b, err := newBlock(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	if target.Schema != nil {
+		b.schema = target.Schema
+	}
+	return b, nil
+}
+
+// UnknownBody reports whether the body of the target block is unknown, so any value is accepted:
+// the embedded AzAPI types only have management plane resources, most data plane resource types aren't in them.
+func UnknownBody(target Target) bool {
+	return target.Block == BlockDataPlaneResource && len(schemaLoader().ListApiVersions(target.ResourceType)) == 0
+}
+
+func newBlock(ctx context.Context, target Target) (*block, error) {
+	name := target.Block
+	if name == "" {
+		name = BlockResource
+	}
+	if name != BlockResourceAction && target.Action != "" {
+		return nil, fmt.Errorf("action is only supported by %s", BlockResourceAction)
+	}
+	switch name {
+	case BlockResource:
+		body, err := getBodyType(ctx, target.ResourceType, target.ApiVersion)
+		if err != nil {
+			return nil, err
+		}
+		return &block{schema: azapi_resource.Resources[name].Block, body: body, rootAttributes: rootAttributes}, nil
+	case BlockUpdateResource:
+		body, err := getBodyType(ctx, target.ResourceType, target.ApiVersion)
+		if err != nil {
+			return nil, err
+		}
+		return &block{schema: azapi_resource.Resources[name].Block, body: body, rootAttributes: []string{"name"}, optional: true}, nil
+	case BlockDataPlaneResource:
+		var body types.TypeBase = &types.AnyType{}
+		if !UnknownBody(target) {
+			bodyType, err := getBodyType(ctx, target.ResourceType, target.ApiVersion)
+			if err != nil {
+				return nil, err
+			}
+			body = bodyType
+		}
+		return &block{schema: azapi_resource.Resources[name].Block, body: body, rootAttributes: []string{"name"}}, nil
+	case BlockDataResource:
+		// The data source has no body, its output is the GET response.
+		body, err := getBodyType(ctx, target.ResourceType, target.ApiVersion)
+		if err != nil {
+			return nil, err
+		}
+		return &block{schema: azapi_resource.DataSources["azapi_resource"].Block, output: body}, nil
+	case BlockResourceAction:
+		if target.Action == "" {
+			return nil, fmt.Errorf("action is required for %s", BlockResourceAction)
+		}
+		function, err := getResourceFunction(ctx, target.ResourceType, target.ApiVersion, target.Action)
+		if err != nil {
+			return nil, err
+		}
+		result := &block{schema: azapi_resource.Resources[name].Block}
+		if function.Input != nil {
+			result.body = function.Input.Type
+		}
+		if function.Output != nil {
+			result.output = function.Output.Type
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("unsupported block type %s, supported block types are %s", name, strings.Join(BlockTypes, ", "))
+}
+
+// attributeType returns the type of the body or output attribute, nil when the block leaves it dynamic.
+func (b *block) attributeType(name string) types.TypeBase {
+	switch name {
+	case "body":
+		return b.body
+	case "output":
+		return b.output
+	}
+	return nil
+}
+
+// converter returns the type converter of the body or output attribute.
+func (b *block) converter(attribute string) *typeConverter {
+	c := newTypeConverter()
+	c.optional = b.optional && attribute == "body"
+	c.response = attribute == "output"
+	return c
+}
+
+// bodyProperties returns the properties of the body the block has as top-level attributes, and the other properties.
+func (b *block) bodyProperties() (root, body map[string]types.ObjectProperty) {
+	root = make(map[string]types.ObjectProperty)
+	body = make(map[string]types.ObjectProperty)
+	object, ok := b.body.(*types.ObjectType)
+	if !ok {
+		return root, body
+	}
+	for name, p := range object.Properties {
+		if slices.Contains(b.rootAttributes, name) && !readOnly(p) {
+			root[name] = p
+			continue
+		}
+		body[name] = p
+	}
+	return root, body
+}
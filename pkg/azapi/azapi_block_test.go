@@ -0,0 +1,108 @@
+package azapi
+
+import (
+	"context"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestGetBlockType_UpdateResource(t *testing.T) {
+	target := Target{Block: BlockUpdateResource, ResourceType: "Microsoft.Network/virtualNetworks", ApiVersion: "2024-05-01"}
+	blockType, err := GetBlockType(context.Background(), target, "")
+	require.NoError(t, err)
+	assert.True(t, blockType.HasAttribute("resource_id"))
+	assert.False(t, blockType.HasAttribute("location"))
+	body := blockType.AttributeType("body")
+	// The update resource patches the resource, location and tags are body properties and nothing is required.
+	assert.True(t, body.HasAttribute("location"))
+	assert.True(t, body.HasAttribute("tags"))
+	assert.True(t, body.AttributeOptional("properties"))
+}
+
+func TestGetBlockType_DataResource(t *testing.T) {
+	target := Target{Block: BlockDataResource, ResourceType: "Microsoft.ContainerRegistry/registries", ApiVersion: "2023-07-01"}
+	blockType, err := GetBlockType(context.Background(), target, "")
+	require.NoError(t, err)
+	assert.False(t, blockType.HasAttribute("body"))
+	assert.True(t, blockType.HasAttribute("resource_id"))
+
+	loginServer, err := GetBlockType(context.Background(), target, "output.properties.loginServer")
+	require.NoError(t, err)
+	assert.Equal(t, cty.String, loginServer)
+}
+
+func TestGetBlockType_DataPlaneResource(t *testing.T) {
+	target := Target{Block: BlockDataPlaneResource, ResourceType: "Microsoft.Purview/accounts/Account/collections", ApiVersion: "2019-11-01-preview"}
+	assert.True(t, UnknownBody(target))
+	blockType, err := GetBlockType(context.Background(), target, "")
+	require.NoError(t, err)
+	assert.Equal(t, cty.DynamicPseudoType, blockType.AttributeType("body"))
+	assert.True(t, blockType.HasAttribute("parent_id"))
+}
+
+func TestGetBlockType_DataPlaneResourceInvalidApiVersion(t *testing.T) {
+	target := Target{Block: BlockDataPlaneResource, ResourceType: "Microsoft.KeyVault/vaults", ApiVersion: "2023-13-01"}
+	assert.False(t, UnknownBody(target))
+	_, err := GetBlockType(context.Background(), target, "")
+	assert.ErrorContains(t, err, "2023-13-01")
+}
+
+func TestGetBlockType_ResourceAction(t *testing.T) {
+	target := Target{Block: BlockResourceAction, ResourceType: "Microsoft.Storage/storageAccounts", ApiVersion: "2023-01-01", Action: "regenerateKey"}
+	blockType, err := GetBlockType(context.Background(), target, "")
+	require.NoError(t, err)
+	assert.True(t, blockType.AttributeType("body").HasAttribute("keyName"))
+	assert.True(t, blockType.AttributeType("output").HasAttribute("keys"))
+	assert.True(t, blockType.HasAttribute("action"))
+
+	_, err = GetBlockType(context.Background(), Target{Block: BlockResourceAction, ResourceType: "Microsoft.Storage/storageAccounts", ApiVersion: "2023-01-01"}, "")
+	assert.ErrorContains(t, err, "action is required")
+}
+
+func TestGetBlockType_Unsupported(t *testing.T) {
+	_, err := GetBlockType(context.Background(), Target{Block: "azapi_resource_list", ResourceType: "Microsoft.Storage/storageAccounts", ApiVersion: "2023-01-01"}, "")
+	assert.ErrorContains(t, err, "unsupported block type azapi_resource_list")
+}
+
+func TestGetBlockDescription_DataResource(t *testing.T) {
+	target := Target{Block: BlockDataResource, ResourceType: "Microsoft.ContainerRegistry/registries", ApiVersion: "2023-07-01"}
+	description, err := GetBlockDescription(context.Background(), target, "")
+	require.NoError(t, err)
+	descriptions := description.(map[string]any)
+	assert.NotContains(t, descriptions, "body")
+	assert.Contains(t, descriptions, "resource_id")
+	loginServer, err := GetBlockDescription(context.Background(), target, "output.properties.loginServer")
+	require.NoError(t, err)
+	assert.Contains(t, loginServer, "(ReadOnly)")
+}
+
+func TestGetBlockOutline_ResourceAction(t *testing.T) {
+	target := Target{Block: BlockResourceAction, ResourceType: "Microsoft.Storage/storageAccounts", ApiVersion: "2023-01-01", Action: "listKeys"}
+	outline, err := GetBlockOutline(context.Background(), target, "output", 2)
+	require.NoError(t, err)
+	text, _ := RenderOutline(outline, false)
+	assert.Contains(t, text, "keys: array<object>")
+}
+
+func TestGetBlockType_ProviderSchema(t *testing.T) {
+	// An older provider version without e.g. sensitive_body.
+	schema := &tfjson.SchemaBlock{Attributes: map[string]*tfjson.SchemaAttribute{
+		"type":      {AttributeType: cty.String, Required: true},
+		"parent_id": {AttributeType: cty.String, Required: true},
+		"body":      {AttributeType: cty.DynamicPseudoType, Optional: true},
+	}}
+	target := Target{ResourceType: "Microsoft.Network/virtualNetworks", ApiVersion: "2024-05-01", Schema: schema}
+	blockType, err := GetBlockType(context.Background(), target, "")
+	require.NoError(t, err)
+	assert.True(t, blockType.HasAttribute("parent_id"))
+	assert.False(t, blockType.HasAttribute("sensitive_body"))
+	assert.True(t, blockType.AttributeType("body").HasAttribute("properties"))
+
+	description, err := GetBlockDescription(context.Background(), target, "")
+	require.NoError(t, err)
+	assert.NotContains(t, description, "sensitive_body")
+}
@@ -0,0 +1,97 @@
+package azapi
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/ms-henglu/go-azure-types/types"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// splitPath splits path at the dots which aren't inside a variant selector, discriminator values can contain dots.
+func splitPath(path string) []string {
+	var segments []string
+	start, inSelector := 0, false
+	for i, c := range path {
+		switch c {
+		case '[':
+			inSelector = true
+		case ']':
+			inSelector = false
+		case '.':
+			if !inSelector {
+				segments = append(segments, path[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(segments, path[start:])
+}
+
+// parseSegment splits a path segment like `properties[type=AzureBlobStorage]` into the property name and the variant selector.
+func parseSegment(segment string) (name, discriminator, value string, err error) {
+	open := strings.Index(segment, "[")
+	if open < 0 {
+		return segment, "", "", nil
+	}
+	selector, ok := strings.CutSuffix(segment[open+1:], "]")
+	if ok {
+		discriminator, value, ok = strings.Cut(selector, "=")
+	}
+	if !ok || discriminator == "" || value == "" {
+		return "", "", "", fmt.Errorf("invalid variant selector in '%s', use name[discriminator=value]", segment)
+	}
+	return segment[:open], discriminator, value, nil
+}
+
+// variantValues returns the sorted discriminator values of the variants of t.
+func variantValues(t *types.DiscriminatedObjectType) []string {
+	values := make([]string, 0, len(t.Elements))
+	for value := range t.Elements {
+		values = append(values, value)
+	}
+	slices.Sort(values)
+	return values
+}
+
+// variantType returns the object type of a variant of t, which has the base properties and the properties of the variant.
+func variantType(t *types.DiscriminatedObjectType, discriminator, value string) (*types.ObjectType, error) {
+	if discriminator != t.Discriminator {
+		return nil, fmt.Errorf("the discriminator is '%s', not '%s'", t.Discriminator, discriminator)
+	}
+	element, ok := t.Elements[value]
+	if !ok {
+		for v, e := range t.Elements {
+			if strings.EqualFold(v, value) {
+				element, ok = e, true
+				break
+			}
+		}
+	}
+	if !ok || element == nil {
+		return nil, fmt.Errorf("unknown %s '%s', possible values are %s", discriminator, value, strings.Join(variantValues(t), ", "))
+	}
+	variant := &types.ObjectType{Properties: make(map[string]types.ObjectProperty)}
+	for name, p := range t.BaseProperties {
+		variant.Properties[name] = p
+	}
+	if object, ok := element.Type.(*types.ObjectType); ok {
+		variant.Name = object.Name
+		for name, p := range object.Properties {
+			variant.Properties[name] = p
+		}
+	}
+	return variant, nil
+}
+
+// discriminatedType stands for a discriminated object, it's dynamic and renders as
+// `DynamicPseudoType /* discriminated by type: A, B, query path[type=<value>] */`.
+func discriminatedType(t *types.DiscriminatedObjectType, path string) cty.Type {
+	return cty.CapsuleWithOps("discriminated "+path, reflect.TypeOf(path), &cty.CapsuleOps{
+		TypeGoString: func(reflect.Type) string {
+			return fmt.Sprintf("cty.DynamicPseudoType /* discriminated by %s: %s, query %s[%s=<value>] */", t.Discriminator, strings.Join(variantValues(t), ", "), path, t.Discriminator)
+		},
+	})
+}
@@ -0,0 +1,101 @@
+package azapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	linkedServiceType       = "Microsoft.DataFactory/factories/linkedservices"
+	linkedServiceApiVersion = "2018-06-01"
+)
+
+func TestSplitPath(t *testing.T) {
+	assert.Equal(t, []string{"properties"}, splitPath("properties"))
+	assert.Equal(t, []string{"properties[type=AzureBlobStorage]", "typeProperties"}, splitPath("properties[type=AzureBlobStorage].typeProperties"))
+	assert.Equal(t, []string{"analyzers[@odata.type=#Microsoft.Azure.Search.StandardAnalyzer]", "name"}, splitPath("analyzers[@odata.type=#Microsoft.Azure.Search.StandardAnalyzer].name"))
+}
+
+func TestParseSegment(t *testing.T) {
+	name, discriminator, value, err := parseSegment("properties[type=AzureBlobStorage]")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"properties", "type", "AzureBlobStorage"}, []string{name, discriminator, value})
+
+	name, discriminator, _, err = parseSegment("properties")
+	require.NoError(t, err)
+	assert.Equal(t, "properties", name)
+	assert.Empty(t, discriminator)
+
+	for _, segment := range []string{"properties[type]", "properties[type=AzureBlobStorage", "properties[=AzureBlobStorage]"} {
+		_, _, _, err = parseSegment(segment)
+		assert.ErrorContains(t, err, "invalid variant selector", segment)
+	}
+}
+
+func TestGetResourceType_DiscriminatedObjectListsVariants(t *testing.T) {
+	resourceType, err := GetResourceType(context.Background(), linkedServiceType, linkedServiceApiVersion, "body.properties")
+	require.NoError(t, err)
+	rendered := compactGoType(resourceType.GoString())
+	assert.Contains(t, rendered, "DynamicPseudoType /* discriminated by type: ")
+	assert.Contains(t, rendered, ", AzureBlobStorage, ")
+	assert.Contains(t, rendered, "query body.properties[type=<value>] */")
+}
+
+func TestGetResourceType_Variant(t *testing.T) {
+	resourceType, err := GetResourceType(context.Background(), linkedServiceType, linkedServiceApiVersion, "body.properties[type=AzureBlobStorage].typeProperties.authenticationType")
+	require.NoError(t, err)
+	assert.Equal(t, "String", compactGoType(resourceType.GoString()))
+
+	variant, err := GetResourceType(context.Background(), linkedServiceType, linkedServiceApiVersion, "body.properties[type=azureblobstorage]")
+	require.NoError(t, err)
+	require.True(t, variant.IsObjectType())
+	assert.True(t, variant.HasAttribute("typeProperties"))
+	assert.True(t, variant.HasAttribute("connectVia"), "base properties are part of the variant")
+}
+
+func TestGetResourceType_VariantErrors(t *testing.T) {
+	_, err := GetResourceType(context.Background(), linkedServiceType, linkedServiceApiVersion, "body.properties.typeProperties")
+	assert.ErrorContains(t, err, "the object is discriminated by type, select a variant with body.properties[type=<value>]")
+	_, err = GetResourceType(context.Background(), linkedServiceType, linkedServiceApiVersion, "body.properties[type=Nope]")
+	assert.ErrorContains(t, err, "unknown type 'Nope', possible values are AmazonMWS, ")
+	_, err = GetResourceType(context.Background(), linkedServiceType, linkedServiceApiVersion, "body.properties[kind=AzureBlobStorage]")
+	assert.ErrorContains(t, err, "the discriminator is 'type', not 'kind'")
+	_, err = GetResourceType(context.Background(), linkedServiceType, linkedServiceApiVersion, "body.properties.connectVia[type=AzureBlobStorage]")
+	assert.ErrorContains(t, err, "is not a discriminated object")
+}
+
+func TestGetResourceSchemaDescription_DiscriminatedObject(t *testing.T) {
+	description, err := GetResourceSchemaDescription(context.Background(), linkedServiceType, linkedServiceApiVersion, "body.properties")
+	require.NoError(t, err)
+	properties, ok := description.(map[string]any)
+	require.True(t, ok)
+	assert.Contains(t, properties, "connectVia")
+	assert.Contains(t, properties["type"], "body.properties[type=AmazonMWS]")
+	assert.Contains(t, properties["type"], "AzureBlobStorage,")
+
+	description, err = GetResourceSchemaDescription(context.Background(), linkedServiceType, linkedServiceApiVersion, "body.properties[type=AzureBlobStorage].typeProperties")
+	require.NoError(t, err)
+	assert.Contains(t, description, "serviceEndpoint")
+}
+
+func TestGetResourceOutline_DiscriminatedObject(t *testing.T) {
+	outline, err := GetResourceOutline(context.Background(), linkedServiceType, linkedServiceApiVersion, "body.properties", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "type", outline.Discriminator)
+	assert.Contains(t, outline.Values, "AzureBlobStorage")
+	text, _ := RenderOutline(outline, true)
+	assert.Contains(t, text, "query body.properties[type=<value>]}")
+
+	outline, err = GetResourceOutline(context.Background(), linkedServiceType, linkedServiceApiVersion, "body.properties[type=AzureBlobStorage]", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "properties[type=AzureBlobStorage]", outline.Name)
+	assert.Empty(t, outline.Discriminator)
+	var names []string
+	for _, p := range outline.Properties {
+		names = append(names, p.Name)
+	}
+	assert.Contains(t, names, "typeProperties")
+}
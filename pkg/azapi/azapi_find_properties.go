@@ -0,0 +1,290 @@
+package azapi
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/logging"
+	"github.com/ms-henglu/go-azure-types/types"
+)
+
+// maxIndexDepth caps the depth of the indexed properties, the bodies of some resource types nest the same objects many times.
+const maxIndexDepth = 10
+
+// propertyIndexes caches a *propertyIndexEntry per resource type of the embedded index, keyed by lower case resource type.
+var propertyIndexes sync.Map
+
+// propertyIndexEntry is the indexed body properties of a resource type at its latest api-version, or why it couldn't be indexed.
+type propertyIndexEntry struct {
+	apiVersion string
+	properties []indexedProperty
+	err        error
+}
+
+// PropertyQuery selects properties of the resource types at their latest api-version, the set fields must all match.
+type PropertyQuery struct {
+	// ResourceType limits the search to a resource type, see selectResourceTypes.
+	ResourceType string
+	// Path matches the property paths from the body root, e.g. properties.privateEndpointNetworkPolicies or identity.type,
+	// or at any depth when it starts with `*.`, e.g. *.privateEndpointNetworkPolicies.
+	Path string
+	// Value matches a possible value of the properties, e.g. UserAssigned.
+	Value string
+	// Description matches the properties whose description has all its words.
+	Description string
+}
+
+// PropertyMatch is a property found by FindProperties.
+type PropertyMatch struct {
+	// Type is the resource type with its latest api-version, e.g. Microsoft.Network/virtualNetworks@2024-05-01.
+	Type string `json:"type"`
+	// Path can be queried with GetResourceSchemaDescription, it selects variants like `properties[kind=X]`.
+	Path        string   `json:"path"`
+	Description string   `json:"description,omitempty"`
+	Values      []string `json:"values,omitempty"`
+	ReadOnly    bool     `json:"read_only,omitempty"`
+}
+
+// indexedProperty is a body property of a resource type, as FindProperties searches it.
+type indexedProperty struct {
+	path string
+	// key is the lower case path without the body prefix and the variant selectors.
+	key         string
+	description string
+	values      []string
+	readOnly    bool
+}
+
+// FindProperties returns the body properties of the resource types matching query, sorted by resource type and path.
+// The first search of a resource type indexes it unless LoadPropertyIndex already did.
+func FindProperties(ctx context.Context, query PropertyQuery) ([]PropertyMatch, error) {
+	if query.Path == "" && query.Value == "" && query.Description == "" {
+		return nil, fmt.Errorf("path, value or description is required")
+	}
+	path, anyDepth := strings.CutPrefix(query.Path, "*.")
+	path = strings.ToLower(normalizePath(path))
+	words := strings.Fields(strings.ToLower(query.Description))
+	resourceTypes, err := selectResourceTypes(query.ResourceType)
+	if err != nil {
+		return nil, err
+	}
+	var matches []PropertyMatch
+	for _, resourceType := range resourceTypes {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		apiVersion, properties, err := latestPropertyIndex(ctx, resourceType)
+		if err != nil {
+			continue
+		}
+		for _, p := range properties {
+			if path != "" && p.key != path && (!anyDepth || !strings.HasSuffix(p.key, "."+path)) {
+				continue
+			}
+			if query.Value != "" && !slices.ContainsFunc(p.values, func(v string) bool { return strings.EqualFold(v, query.Value) }) {
+				continue
+			}
+			if !containsWords(p.description, words) {
+				continue
+			}
+			matches = append(matches, PropertyMatch{
+				Type:        resourceType + "@" + apiVersion,
+				Path:        p.path,
+				Description: p.description,
+				Values:      p.values,
+				ReadOnly:    p.readOnly,
+			})
+		}
+	}
+	return matches, nil
+}
+
+// selectResourceTypes returns the sorted resource types a filter selects, case-insensitively, each in a single casing.
+// A filter ending with / selects the resource types starting with it, e.g. Microsoft.Network/ or Microsoft.Network/virtualNetworks/,
+// another filter is a resource type, its child resource types aren't selected. An empty filter selects all resource types.
+func selectResourceTypes(filter string) ([]string, error) {
+	schema := schemaLoader().GetSchema()
+	if schema == nil {
+		return nil, fmt.Errorf("failed to load the embedded azapi type index")
+	}
+	lowerFilter := strings.ToLower(filter)
+	prefix := filter == "" || strings.HasSuffix(filter, "/")
+	var resourceTypes []string
+	for resourceType := range schema.Resources {
+		lower := strings.ToLower(resourceType)
+		if lower == lowerFilter || (prefix && strings.HasPrefix(lower, lowerFilter)) {
+			resourceTypes = append(resourceTypes, resourceType)
+		}
+	}
+	if len(resourceTypes) == 0 && !prefix {
+		return nil, fmt.Errorf("resource type %s not found, end it with / to select the resource types starting with it", filter)
+	}
+	slices.SortFunc(resourceTypes, func(a, b string) int {
+		return cmp.Or(cmp.Compare(strings.ToLower(a), strings.ToLower(b)), cmp.Compare(a, b))
+	})
+	// The index has some types in several casings, e.g. Microsoft.Network/virtualnetworks/subnets.
+	return slices.CompactFunc(resourceTypes, strings.EqualFold), nil
+}
+
+// latestApiVersion returns the latest stable api-version of a resource type, or the latest preview when it has no stable one.
+func latestApiVersion(resourceType string) (string, error) {
+	versions, err := GetApiVersions(resourceType)
+	if err != nil {
+		return "", err
+	}
+	for _, v := range slices.Backward(versions) {
+		if !strings.Contains(strings.ToLower(v), "preview") {
+			return v, nil
+		}
+	}
+	return versions[len(versions)-1], nil
+}
+
+// latestPropertyIndex returns the latest api-version of a resource type and its indexed body properties.
+// A resource type that can't be indexed is logged once, its error is cached like its properties.
+func latestPropertyIndex(ctx context.Context, resourceType string) (string, []indexedProperty, error) {
+	key := strings.ToLower(resourceType)
+	if entry, ok := propertyIndexes.Load(key); ok {
+		entry := entry.(*propertyIndexEntry)
+		return entry.apiVersion, entry.properties, entry.err
+	}
+	entry := &propertyIndexEntry{}
+	entry.apiVersion, entry.err = latestApiVersion(resourceType)
+	if entry.err == nil {
+		entry.properties, entry.err = propertyIndex(ctx, resourceType, entry.apiVersion)
+	}
+	if actual, loaded := propertyIndexes.LoadOrStore(key, entry); loaded {
+		entry = actual.(*propertyIndexEntry)
+	} else if entry.err != nil {
+		logging.FromContext(ctx).Warn("resource type left out of the property searches", "resource_type", resourceType, "error", entry.err)
+	}
+	return entry.apiVersion, entry.properties, entry.err
+}
+
+func propertyIndex(ctx context.Context, resourceType, apiVersion string) ([]indexedProperty, error) {
+	bodyType, err := getBodyType(ctx, resourceType, apiVersion)
+	if err != nil {
+		return nil, err
+	}
+	var properties []indexedProperty
+	indexProperties(bodyType, "body", 0, false, make(map[types.TypeBase]bool), &properties)
+	return properties, nil
+}
+
+// LoadPropertyIndex indexes the body properties of all resource types, so that the first property search doesn't pay for it.
+func LoadPropertyIndex(ctx context.Context) error {
+	resourceTypes, err := selectResourceTypes("")
+	if err != nil {
+		return err
+	}
+	for _, resourceType := range resourceTypes {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		_, _, _ = latestPropertyIndex(ctx, resourceType)
+	}
+	return nil
+}
+
+// indexProperties appends the properties of t and of the objects nested in it, the variants of discriminated objects included.
+// The properties nested in a read-only property are read-only too.
+func indexProperties(t types.TypeBase, path string, depth int, parentReadOnly bool, ancestors map[types.TypeBase]bool, properties *[]indexedProperty) {
+	owner := propertiesOwner(t)
+	if owner == nil || ancestors[owner] || depth >= maxIndexDepth {
+		return
+	}
+	ancestors[owner] = true
+	defer delete(ancestors, owner)
+	add := func(name string, p types.ObjectProperty, path string) {
+		if p.Type == nil {
+			return
+		}
+		propertyPath := joinPath(path, name)
+		values, _ := enumValues(p.Type.Type)
+		description := ""
+		if p.Description != nil {
+			description = *p.Description
+		}
+		isReadOnly := parentReadOnly || readOnly(p)
+		*properties = append(*properties, indexedProperty{
+			path:        propertyPath,
+			key:         strings.ToLower(normalizePath(propertyPath)),
+			description: description,
+			values:      values,
+			readOnly:    isReadOnly,
+		})
+		indexProperties(p.Type.Type, propertyPath, depth+1, isReadOnly, ancestors, properties)
+	}
+	switch owner := owner.(type) {
+	case *types.ObjectType:
+		for _, name := range sortedNames(owner.Properties) {
+			add(name, owner.Properties[name], path)
+		}
+	case *types.DiscriminatedObjectType:
+		if _, ok := owner.BaseProperties[owner.Discriminator]; !ok {
+			*properties = append(*properties, indexedProperty{
+				path:     joinPath(path, owner.Discriminator),
+				key:      strings.ToLower(normalizePath(joinPath(path, owner.Discriminator))),
+				values:   variantValues(owner),
+				readOnly: parentReadOnly,
+			})
+		}
+		for _, name := range sortedNames(owner.BaseProperties) {
+			add(name, owner.BaseProperties[name], path)
+		}
+		for _, value := range variantValues(owner) {
+			element := owner.Elements[value]
+			if element == nil {
+				continue
+			}
+			variant, ok := element.Type.(*types.ObjectType)
+			if !ok {
+				continue
+			}
+			variantPath := fmt.Sprintf("%s[%s=%s]", path, owner.Discriminator, value)
+			for _, name := range sortedNames(variant.Properties) {
+				if _, ok := owner.BaseProperties[name]; !ok && name != owner.Discriminator {
+					add(name, variant.Properties[name], variantPath)
+				}
+			}
+		}
+	}
+}
+
+// normalizePath removes the body prefix and the variant selectors of a path, e.g. body.properties[kind=X].foo is properties.foo.
+func normalizePath(path string) string {
+	path = strings.TrimPrefix(path, "body.")
+	if path == "body" {
+		return ""
+	}
+	segments := splitPath(path)
+	for i, segment := range segments {
+		if open := strings.Index(segment, "["); open >= 0 {
+			segments[i] = segment[:open]
+		}
+	}
+	return strings.Join(segments, ".")
+}
+
+func containsWords(text string, words []string) bool {
+	text = strings.ToLower(text)
+	for _, w := range words {
+		if !strings.Contains(text, w) {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedNames(properties map[string]types.ObjectProperty) []string {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,99 @@
+package azapi
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindProperties_ByPath(t *testing.T) {
+	matches, err := FindProperties(context.Background(), PropertyQuery{ResourceType: "Microsoft.Network/virtualNetworks/", Path: "body.properties.privateEndpointNetworkPolicies"})
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Regexp(t, `^Microsoft.Network/virtualNetworks/subnets@\d{4}-\d{2}-\d{2}$`, matches[0].Type)
+	assert.Equal(t, "body.properties.privateEndpointNetworkPolicies", matches[0].Path)
+	assert.Contains(t, matches[0].Values, "Disabled")
+	assert.False(t, matches[0].ReadOnly)
+}
+
+func TestFindProperties_AnyDepth(t *testing.T) {
+	matches, err := FindProperties(context.Background(), PropertyQuery{ResourceType: "Microsoft.Network/virtualNetworks", Path: "*.subnets.properties.privateEndpointNetworkPolicies"})
+	require.NoError(t, err)
+	paths := make(map[string]string)
+	for _, m := range matches {
+		paths[m.Path] = m.Type
+	}
+	assert.Contains(t, paths, "body.properties.subnets.properties.privateEndpointNetworkPolicies")
+}
+
+func TestFindProperties_ResourceTypeExcludesChildTypes(t *testing.T) {
+	matches, err := FindProperties(context.Background(), PropertyQuery{ResourceType: "microsoft.network/virtualnetworks", Path: "*.privateEndpointNetworkPolicies"})
+	require.NoError(t, err)
+	require.NotEmpty(t, matches)
+	for _, m := range matches {
+		assert.Regexp(t, `^Microsoft.Network/virtualNetworks@`, m.Type)
+	}
+
+	_, err = FindProperties(context.Background(), PropertyQuery{ResourceType: "Microsoft.Network", Path: "identity.type"})
+	assert.ErrorContains(t, err, "end it with /")
+}
+
+func TestFindProperties_ByValue(t *testing.T) {
+	matches, err := FindProperties(context.Background(), PropertyQuery{ResourceType: "Microsoft.ContainerRegistry/registries", Path: "identity.type", Value: "userassigned"})
+	require.NoError(t, err)
+	require.NotEmpty(t, matches)
+	assert.Regexp(t, `^Microsoft.ContainerRegistry/registries@`, matches[0].Type)
+
+	matches, err = FindProperties(context.Background(), PropertyQuery{ResourceType: "Microsoft.ContainerRegistry/registries", Path: "identity.type", Value: "Bogus"})
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+}
+
+func TestFindProperties_SelectsVariants(t *testing.T) {
+	matches, err := FindProperties(context.Background(), PropertyQuery{ResourceType: "Microsoft.DataFactory/factories/linkedservices", Path: "properties.typeProperties.url"})
+	require.NoError(t, err)
+	paths := make([]string, 0, len(matches))
+	for _, m := range matches {
+		paths = append(paths, m.Path)
+	}
+	assert.Contains(t, paths, "body.properties[type=HttpServer].typeProperties.url")
+}
+
+func TestFindProperties_ByDescription(t *testing.T) {
+	matches, err := FindProperties(context.Background(), PropertyQuery{ResourceType: "Microsoft.ContainerRegistry/registries", Path: "*.adminUserEnabled", Description: "ADMIN user"})
+	require.NoError(t, err)
+	require.NotEmpty(t, matches)
+	assert.Equal(t, "body.properties.adminUserEnabled", matches[0].Path)
+}
+
+func TestFindProperties_NoCriteria(t *testing.T) {
+	_, err := FindProperties(context.Background(), PropertyQuery{ResourceType: "Microsoft.Network"})
+	assert.ErrorContains(t, err, "path, value or description is required")
+}
+
+func TestNormalizePath(t *testing.T) {
+	assert.Equal(t, "properties.typeProperties.url", normalizePath("body.properties[type=HttpServer].typeProperties.url"))
+	assert.Equal(t, "identity.type", normalizePath("identity.type"))
+	assert.Equal(t, "", normalizePath("body"))
+}
+
+func TestFindProperties_UnindexedResourceTypeIsLoggedOnce(t *testing.T) {
+	var logs bytes.Buffer
+	ctx := logging.WithLogger(context.Background(), slog.New(slog.NewTextHandler(&logs, nil)))
+	for range 2 {
+		_, err := FindProperties(ctx, PropertyQuery{
+			ResourceType: "Microsoft.ApiManagement/service/portalsettings",
+			Path:         "properties.enabled",
+		})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, strings.Count(logs.String(), "resource_type=Microsoft.ApiManagement/service/portalsettings"))
+	assert.Contains(t, logs.String(), "left out of the property searches")
+}
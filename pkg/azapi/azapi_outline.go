@@ -0,0 +1,318 @@
+package azapi
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/ms-henglu/go-azure-types/types"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// OutlineProperty is a property of a resource outline, with its nested properties down to the outline depth.
+type OutlineProperty struct {
+	Name        string
+	Path        string
+	Type        string
+	Flags       []string
+	Description string
+	// Constraints are the value constraints, e.g. `Max length: 24`.
+	Constraints []string
+	// Values are the possible values of an enum, or the variants of a discriminated object.
+	Values []string
+	// Discriminator is the property telling the variants of a discriminated object apart.
+	Discriminator string
+	Properties    []OutlineProperty
+	// Ref is the path of the outer property of the same type, when the type is nested in itself.
+	Ref string
+	// Collapsed is the number of nested properties left out because they are deeper than the outline depth.
+	Collapsed int
+}
+
+// GetResourceOutline returns the property at path, e.g. body.properties, with its nested properties down to depth levels.
+// An empty path returns the azapi_resource attributes, with the body of the resource type.
+func GetResourceOutline(ctx context.Context, resourceType, apiVersion, path string, depth int) (*OutlineProperty, error) {
+	return GetBlockOutline(ctx, Target{ResourceType: resourceType, ApiVersion: apiVersion}, path, depth)
+}
+
+// GetBlockOutline outlines the target block like GetResourceOutline outlines azapi_resource.
+func GetBlockOutline(ctx context.Context, target Target, path string, depth int) (*OutlineProperty, error) {
+	b, err := getBlock(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	return outlineAt(b.objectType(), "", path, depth)
+}
+
+// outlineAt outlines the property at path in root, prefix is the path of root. An empty path outlines root without a name.
+func outlineAt(root types.TypeBase, prefix, path string, depth int) (*OutlineProperty, error) {
+	property := types.ObjectProperty{Type: &types.TypeReference{Type: root}}
+	name := ""
+	if path != "" {
+		segments := splitPath(path)
+		name = segments[len(segments)-1]
+	}
+	if rest := strings.TrimPrefix(strings.TrimPrefix(path, prefix), "."); rest != "" {
+		var err error
+		if property, err = findProperty(root, prefix, rest); err != nil {
+			return nil, err
+		}
+	}
+	outline := outlineProperty(name, path, property, depth, make(map[types.TypeBase]string))
+	return &outline, nil
+}
+
+// objectType merges the block attributes with the body and output types, so they're all outlined the same way.
+func (b *block) objectType() *types.ObjectType {
+	object := blockObjectType(b.schema)
+	for _, attribute := range []string{"body", "output"} {
+		if t := b.attributeType(attribute); t != nil {
+			property := object.Properties[attribute]
+			property.Type = &types.TypeReference{Type: t}
+			object.Properties[attribute] = property
+		}
+	}
+	return object
+}
+
+// outlineProperty outlines property, ancestors are the objects being outlined with their path.
+func outlineProperty(name, path string, property types.ObjectProperty, depth int, ancestors map[types.TypeBase]string) OutlineProperty {
+	outline := OutlineProperty{
+		Name:  name,
+		Path:  path,
+		Type:  typeName(property.Type.Type),
+		Flags: flagNames(property.Flags),
+	}
+	if property.Description != nil {
+		outline.Description = *property.Description
+	}
+	if outline.Type == "enum" {
+		outline.Values = getPossibleValues(property)
+	}
+	outline.Constraints = getConstraints(property.Type.Type)
+	owner := propertiesOwner(property.Type.Type)
+	if owner == nil {
+		return outline
+	}
+	if d, ok := owner.(*types.DiscriminatedObjectType); ok {
+		outline.Discriminator = d.Discriminator
+		outline.Values = variantValues(d)
+	}
+	if ref, ok := ancestors[owner]; ok {
+		outline.Ref = ref
+		return outline
+	}
+	properties := objectProperties(owner)
+	if depth <= 0 {
+		outline.Collapsed = len(properties)
+		return outline
+	}
+	names := make([]string, 0, len(properties))
+	for n, p := range properties {
+		if p.Type != nil {
+			names = append(names, n)
+		}
+	}
+	slices.Sort(names)
+	ancestors[owner] = path
+	defer delete(ancestors, owner)
+	for _, n := range names {
+		outline.Properties = append(outline.Properties, outlineProperty(n, joinPath(path, n), properties[n], depth-1, ancestors))
+	}
+	return outline
+}
+
+func typeName(t types.TypeBase) string {
+	switch t := t.(type) {
+	case *types.StringType:
+		return "string"
+	case *types.IntegerType:
+		return "int"
+	case *numberType:
+		return "number"
+	case *types.BooleanType:
+		return "bool"
+	case *types.StringLiteralType:
+		return fmt.Sprintf("%q", t.Value)
+	case *types.UnionType:
+		for _, e := range t.Elements {
+			switch e.Type.(type) {
+			case *types.StringLiteralType, *types.StringType:
+			default:
+				return "union"
+			}
+		}
+		return "enum"
+	case *types.ObjectType:
+		if len(t.Properties) == 0 && t.AdditionalProperties != nil {
+			return fmt.Sprintf("map<%s>", typeName(t.AdditionalProperties.Type))
+		}
+		return "object"
+	case *types.DiscriminatedObjectType:
+		return "object"
+	case *types.ArrayType:
+		if t.ItemType == nil {
+			return "array<any>"
+		}
+		return fmt.Sprintf("array<%s>", typeName(t.ItemType.Type))
+	}
+	return "any"
+}
+
+func flagNames(flags []types.ObjectPropertyFlag) []string {
+	var names []string
+	for _, flag := range flags {
+		switch flag {
+		case types.Required:
+			names = append(names, "Required")
+		case types.ReadOnly:
+			names = append(names, "ReadOnly")
+		case types.WriteOnly:
+			names = append(names, "WriteOnly")
+		case types.DeployTimeConstant:
+			names = append(names, "DeployTimeConstant")
+		case types.Identifier:
+			names = append(names, "Identifier")
+		}
+	}
+	return names
+}
+
+// RenderOutline renders an outline as indented `name: type (flags)` lines, with the descriptions, possible values and constraints when descriptions is true.
+// The paths of the collapsed objects are returned so they can be queried next.
+func RenderOutline(outline *OutlineProperty, descriptions bool) (string, []string) {
+	var sb strings.Builder
+	var collapsed []string
+	var render func(p OutlineProperty, indent int)
+	render = func(p OutlineProperty, indent int) {
+		sb.WriteString(strings.Repeat("  ", indent))
+		fmt.Fprintf(&sb, "%s: %s", p.Name, p.Type)
+		if len(p.Flags) > 0 {
+			fmt.Fprintf(&sb, " (%s)", strings.Join(p.Flags, ", "))
+		}
+		if p.Discriminator != "" {
+			fmt.Fprintf(&sb, " {discriminated by %s: %s, query %s[%s=<value>]}", p.Discriminator, strings.Join(p.Values, ", "), p.Path, p.Discriminator)
+		}
+		if p.Ref != "" {
+			fmt.Fprintf(&sb, " {$ref: %s}", p.Ref)
+		}
+		if p.Collapsed > 0 {
+			fmt.Fprintf(&sb, " {... %d properties}", p.Collapsed)
+			collapsed = append(collapsed, p.Path)
+		}
+		if descriptions && p.Description != "" {
+			fmt.Fprintf(&sb, " - %s", strings.Join(strings.Fields(p.Description), " "))
+		}
+		if descriptions && len(p.Values) > 0 && p.Discriminator == "" {
+			fmt.Fprintf(&sb, " (Possible values: %s)", strings.Join(p.Values, ","))
+		}
+		if descriptions {
+			for _, constraint := range p.Constraints {
+				fmt.Fprintf(&sb, " (%s)", constraint)
+			}
+		}
+		sb.WriteString("\n")
+		for _, child := range p.Properties {
+			render(child, indent+1)
+		}
+	}
+	if outline.Name == "" {
+		for _, p := range outline.Properties {
+			render(p, 0)
+		}
+	} else {
+		render(*outline, 0)
+	}
+	return strings.TrimSuffix(sb.String(), "\n"), collapsed
+}
+
+// blockObjectType converts a Terraform schema block to an AzAPI object type.
+func blockObjectType(block *tfjson.SchemaBlock) *types.ObjectType {
+	object := attributesObjectType(block.Attributes)
+	for name, nestedBlock := range block.NestedBlocks {
+		var flags []types.ObjectPropertyFlag
+		if nestedBlock.MinItems > 0 {
+			flags = append(flags, types.Required)
+		}
+		object.Properties[name] = types.ObjectProperty{
+			Type:        &types.TypeReference{Type: nestedType(blockObjectType(nestedBlock.Block), nestedBlock.NestingMode)},
+			Flags:       flags,
+			Description: description(nestedBlock.Block.Description),
+		}
+	}
+	return object
+}
+
+func attributesObjectType(attributes map[string]*tfjson.SchemaAttribute) *types.ObjectType {
+	object := &types.ObjectType{Properties: make(map[string]types.ObjectProperty)}
+	for name, attr := range attributes {
+		var t types.TypeBase
+		if attr.AttributeNestedType != nil {
+			t = nestedType(attributesObjectType(attr.AttributeNestedType.Attributes), attr.AttributeNestedType.NestingMode)
+		} else {
+			t = ctyAzAPIType(attr.AttributeType)
+		}
+		var flags []types.ObjectPropertyFlag
+		if attr.Required {
+			flags = append(flags, types.Required)
+		}
+		if attr.Computed && !attr.Optional && !attr.Required {
+			flags = append(flags, types.ReadOnly)
+		}
+		if attr.WriteOnly {
+			flags = append(flags, types.WriteOnly)
+		}
+		object.Properties[name] = types.ObjectProperty{
+			Type:        &types.TypeReference{Type: t},
+			Flags:       flags,
+			Description: description(attr.Description),
+		}
+	}
+	return object
+}
+
+func nestedType(object *types.ObjectType, mode tfjson.SchemaNestingMode) types.TypeBase {
+	switch mode {
+	case tfjson.SchemaNestingModeList, tfjson.SchemaNestingModeSet:
+		return &types.ArrayType{ItemType: &types.TypeReference{Type: object}}
+	case tfjson.SchemaNestingModeMap:
+		return &types.ObjectType{AdditionalProperties: &types.TypeReference{Type: object}}
+	}
+	return object
+}
+
+func ctyAzAPIType(t cty.Type) types.TypeBase {
+	switch {
+	case t == cty.String:
+		return &types.StringType{}
+	case t == cty.Number:
+		return &numberType{}
+	case t == cty.Bool:
+		return &types.BooleanType{}
+	case t.IsListType() || t.IsSetType():
+		return &types.ArrayType{ItemType: &types.TypeReference{Type: ctyAzAPIType(t.ElementType())}}
+	case t.IsMapType():
+		return &types.ObjectType{AdditionalProperties: &types.TypeReference{Type: ctyAzAPIType(t.ElementType())}}
+	case t.IsObjectType():
+		object := &types.ObjectType{Properties: make(map[string]types.ObjectProperty)}
+		for name, at := range t.AttributeTypes() {
+			object.Properties[name] = types.ObjectProperty{Type: &types.TypeReference{Type: ctyAzAPIType(at)}}
+		}
+		return object
+	}
+	return &types.AnyType{}
+}
+
+// numberType stands for cty.Number, AzAPI types only have integers.
+type numberType struct {
+	types.AnyType
+}
+
+func description(d string) *string {
+	if d == "" {
+		return nil
+	}
+	return &d
+}
@@ -0,0 +1,77 @@
+package azapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetResourceOutline_SummarizesDeeperLevels(t *testing.T) {
+	outline, err := GetResourceOutline(context.Background(), "Microsoft.Network/applicationGateways", "2024-05-01", "body", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "body", outline.Name)
+	assert.Equal(t, "object", outline.Type)
+	var properties *OutlineProperty
+	for i, p := range outline.Properties {
+		if p.Name == "properties" {
+			properties = &outline.Properties[i]
+		}
+	}
+	require.NotNil(t, properties)
+	assert.Equal(t, "body.properties", properties.Path)
+	assert.Empty(t, properties.Properties)
+	assert.Greater(t, properties.Collapsed, 10)
+
+	_, collapsed := RenderOutline(outline, false)
+	assert.Contains(t, collapsed, "body.properties")
+}
+
+func TestGetResourceOutline_PathThroughArray(t *testing.T) {
+	outline, err := GetResourceOutline(context.Background(), "Microsoft.Network/applicationGateways", "2024-05-01", "body.properties.probes.properties.protocol", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "enum", outline.Type)
+	assert.Contains(t, outline.Values, "Https")
+}
+
+func TestGetResourceOutline_AzAPIAttributes(t *testing.T) {
+	outline, err := GetResourceOutline(context.Background(), "Microsoft.Network/applicationGateways", "2024-05-01", "", 2)
+	require.NoError(t, err)
+	text, _ := RenderOutline(outline, false)
+	assert.Contains(t, text, "\ntype: string (Required)\n")
+	assert.Contains(t, text, "\nretry: object\n  error_message_regex: array<string> (Required)\n  interval_seconds: number\n")
+	assert.Contains(t, text, "  name: string (Required, DeployTimeConstant)\n")
+}
+
+func TestGetResourceOutline_UnknownPath(t *testing.T) {
+	_, err := GetResourceOutline(context.Background(), "Microsoft.Network/applicationGateways", "2024-05-01", "body.properties.nope", 1)
+	require.ErrorContains(t, err, "property 'nope' not found at path 'body.properties.nope'")
+}
+
+func TestRenderOutline(t *testing.T) {
+	outline := &OutlineProperty{
+		Name: "sku",
+		Path: "body.sku",
+		Type: "object",
+		Properties: []OutlineProperty{
+			{Name: "name", Path: "body.sku.name", Type: "enum", Flags: []string{"Required"}, Description: "The SKU\n name.", Values: []string{"Basic", "Standard"}},
+			{Name: "capacity", Path: "body.sku.capacity", Type: "object", Collapsed: 2},
+		},
+	}
+	text, collapsed := RenderOutline(outline, false)
+	assert.Equal(t, "sku: object\n  name: enum (Required)\n  capacity: object {... 2 properties}", text)
+	assert.Equal(t, []string{"body.sku.capacity"}, collapsed)
+
+	text, _ = RenderOutline(outline, true)
+	assert.Equal(t, "sku: object\n  name: enum (Required) - The SKU name. (Possible values: Basic,Standard)\n  capacity: object {... 2 properties}", text)
+}
+
+func TestGetResourceOutline_Constraints(t *testing.T) {
+	outline, err := GetResourceOutline(context.Background(), "Microsoft.Storage/storageAccounts", "2023-05-01", "body.name", 1)
+	require.NoError(t, err)
+	text, _ := RenderOutline(outline, true)
+	assert.Equal(t, "name: string (Required, DeployTimeConstant) - The resource name (Min length: 3) (Max length: 24) (Pattern: ^[a-z0-9]+$)", text)
+	text, _ = RenderOutline(outline, false)
+	assert.Equal(t, "name: string (Required, DeployTimeConstant)", text)
+}
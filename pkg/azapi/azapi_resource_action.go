@@ -0,0 +1,173 @@
+package azapi
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/tracing"
+	"github.com/ms-henglu/go-azure-types/types"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ResourceAction is a POST operation of a resource type, e.g. listKeys, called with azapi_resource_action.
+type ResourceAction struct {
+	// Type is the value of the azapi_resource_action `type` attribute.
+	Type   string `json:"type"`
+	Action string `json:"action"`
+	Method string `json:"method"`
+	// RequestBody and Response are described like the resource body, they're nil when the action has none.
+	RequestBody any `json:"request_body,omitempty"`
+	Response    any `json:"response,omitempty"`
+}
+
+// ResourceList tells how to list the resources of a type with azapi_resource_list.
+type ResourceList struct {
+	// Type is the value of the azapi_resource_list `type` attribute.
+	Type string `json:"type"`
+	// ParentIDs are the formats of `parent_id`, one per scope the resources are deployed at.
+	ParentIDs []string `json:"parent_ids"`
+	// Response describes the list response, its value is a list of the resource body with the ReadOnly properties.
+	Response map[string]any `json:"response"`
+}
+
+// ListResourceActions returns the sorted names of the actions of a resource type.
+func ListResourceActions(ctx context.Context, resourceType, apiVersion string) ([]string, error) {
+	functions, err := loadResourceFunctions(ctx, resourceType, apiVersion)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(functions))
+	for _, f := range functions {
+		names = append(names, f.Name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// GetResourceActionDescription describes the request body and response of an action, or the property at path,
+// e.g. response.keys, the path starts with request_body or response.
+func GetResourceActionDescription(ctx context.Context, resourceType, apiVersion, action, path string) (any, error) {
+	function, err := getResourceFunction(ctx, resourceType, apiVersion, action)
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		result := &ResourceAction{
+			Type:   function.ResourceType + "@" + function.ApiVersion,
+			Action: function.Name,
+			Method: "POST",
+		}
+		if function.Input != nil {
+			if result.RequestBody, err = describeType(function.Input.Type, "request_body"); err != nil {
+				return nil, err
+			}
+		}
+		if function.Output != nil {
+			if result.Response, err = describeType(function.Output.Type, "response"); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+	root, rest, _ := strings.Cut(path, ".")
+	var body *types.TypeReference
+	switch root {
+	case "request_body":
+		body = function.Input
+	case "response":
+		body = function.Output
+	default:
+		return nil, fmt.Errorf("invalid path '%s', it must start with request_body or response", path)
+	}
+	if body == nil {
+		return nil, fmt.Errorf("action %s has no %s", function.Name, root)
+	}
+	if rest == "" {
+		return describeType(body.Type, root)
+	}
+	property, err := findProperty(body.Type, root, rest)
+	if err != nil {
+		return nil, err
+	}
+	return convertPropertyToMap(property, path, make(map[types.TypeBase]string))
+}
+
+// GetResourceList returns the parent_id formats the resources of a type are listed at, and the shape of the list response.
+func GetResourceList(ctx context.Context, resourceType, apiVersion string) (*ResourceList, error) {
+	scope, err := GetResourceScope(ctx, resourceType, apiVersion)
+	if err != nil {
+		return nil, err
+	}
+	bodyType, err := getBodyType(ctx, resourceType, apiVersion)
+	if err != nil {
+		return nil, err
+	}
+	value, err := describeType(bodyType, "value")
+	if err != nil {
+		return nil, err
+	}
+	result := &ResourceList{
+		Type: scope.Type,
+		Response: map[string]any{
+			"value":    []any{value},
+			"nextLink": "The URL of the next page, azapi_resource_list follows it and returns the resources of all pages in `value`. (ReadOnly)",
+		},
+	}
+	for _, s := range scope.Scopes {
+		result.ParentIDs = append(result.ParentIDs, s.ParentID)
+	}
+	return result, nil
+}
+
+// describeType describes a request or response body, an array of objects is described as a list of its element.
+func describeType(t types.TypeBase, path string) (any, error) {
+	if array, ok := t.(*types.ArrayType); ok && array.ItemType != nil && propertiesOwner(array.ItemType.Type) != nil {
+		item, err := describeType(array.ItemType.Type, path)
+		if err != nil {
+			return nil, err
+		}
+		return []any{item}, nil
+	}
+	return convertPropertyToMap(types.ObjectProperty{Type: &types.TypeReference{Type: t}}, path, make(map[types.TypeBase]string))
+}
+
+func getResourceFunction(ctx context.Context, resourceType, apiVersion, action string) (*types.ResourceFunctionType, error) {
+	functions, err := loadResourceFunctions(ctx, resourceType, apiVersion)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(functions))
+	for _, f := range functions {
+		if strings.EqualFold(f.Name, action) {
+			return f, nil
+		}
+		names = append(names, f.Name)
+	}
+	slices.Sort(names)
+	return nil, fmt.Errorf("action %s not found for resource %s api-version %s, available actions: %s", action, resourceType, apiVersion, strings.Join(names, ","))
+}
+
+func loadResourceFunctions(ctx context.Context, resourceType, apiVersion string) (_ []*types.ResourceFunctionType, err error) {
+	_, span := tracing.Start(ctx, "azapi.load_functions",
+		attribute.String("azapi.resource_type", resourceType),
+		attribute.String("azapi.api_version", apiVersion),
+	)
+	defer func() { tracing.End(span, err) }()
+	definitions, err := schemaLoader().ListResourceFunctions(resourceType, apiVersion)
+	if err != nil {
+		return nil, err
+	}
+	functions := make([]*types.ResourceFunctionType, 0, len(definitions))
+	for _, d := range definitions {
+		function, err := d.GetDefinition()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load actions of resource %s api-version %s: %w", resourceType, apiVersion, err)
+		}
+		if function != nil {
+			functions = append(functions, function)
+		}
+	}
+	return functions, nil
+}
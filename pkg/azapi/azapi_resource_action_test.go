@@ -0,0 +1,76 @@
+package azapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListResourceActions(t *testing.T) {
+	actions, err := ListResourceActions(context.Background(), "Microsoft.Storage/storageAccounts", "2023-01-01")
+	require.NoError(t, err)
+	assert.Contains(t, actions, "listKeys")
+	assert.Contains(t, actions, "regenerateKey")
+	assert.IsNonDecreasing(t, actions)
+}
+
+func TestGetResourceActionDescription_RequestAndResponse(t *testing.T) {
+	description, err := GetResourceActionDescription(context.Background(), "Microsoft.Storage/storageAccounts", "2023-01-01", "regeneratekey", "")
+	require.NoError(t, err)
+	action, ok := description.(*ResourceAction)
+	require.True(t, ok)
+	assert.Equal(t, "Microsoft.Storage/storageAccounts@2023-01-01", action.Type)
+	assert.Equal(t, "regenerateKey", action.Action)
+	assert.Equal(t, "POST", action.Method)
+	request, ok := action.RequestBody.(map[string]any)
+	require.True(t, ok)
+	assert.Contains(t, request["keyName"], "(Required)")
+	response, ok := action.Response.(map[string]any)
+	require.True(t, ok)
+	assert.Contains(t, response, "keys")
+}
+
+func TestGetResourceActionDescription_NoRequestBody(t *testing.T) {
+	description, err := GetResourceActionDescription(context.Background(), "Microsoft.Compute/virtualMachines", "2024-07-01", "restart", "")
+	require.NoError(t, err)
+	action := description.(*ResourceAction)
+	assert.Nil(t, action.RequestBody)
+	assert.Nil(t, action.Response)
+}
+
+func TestGetResourceActionDescription_Path(t *testing.T) {
+	description, err := GetResourceActionDescription(context.Background(), "Microsoft.Storage/storageAccounts", "2023-01-01", "listKeys", "response.keys.value")
+	require.NoError(t, err)
+	assert.IsType(t, "", description)
+	assert.Contains(t, description, "(ReadOnly)")
+
+	_, err = GetResourceActionDescription(context.Background(), "Microsoft.Storage/storageAccounts", "2023-01-01", "listKeys", "request_body")
+	assert.ErrorContains(t, err, "action listKeys has no request_body")
+	_, err = GetResourceActionDescription(context.Background(), "Microsoft.Storage/storageAccounts", "2023-01-01", "listKeys", "body.keys")
+	assert.ErrorContains(t, err, "must start with request_body or response")
+}
+
+func TestGetResourceActionDescription_UnknownAction(t *testing.T) {
+	_, err := GetResourceActionDescription(context.Background(), "Microsoft.Storage/storageAccounts", "2023-01-01", "reboot", "")
+	assert.ErrorContains(t, err, "action reboot not found")
+	assert.ErrorContains(t, err, "listKeys")
+}
+
+func TestGetResourceList(t *testing.T) {
+	list, err := GetResourceList(context.Background(), "Microsoft.Network/virtualNetworks/subnets", "2024-05-01")
+	require.NoError(t, err)
+	assert.Equal(t, "Microsoft.Network/virtualNetworks/subnets@2024-05-01", list.Type)
+	assert.Equal(t, []string{"/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.Network/virtualNetworks/{virtualNetworksName}"}, list.ParentIDs)
+	require.IsType(t, []any{}, list.Response["value"])
+	value := list.Response["value"].([]any)
+	require.Len(t, value, 1)
+	resource, ok := value[0].(map[string]any)
+	require.True(t, ok)
+	properties, ok := resource["properties"].(map[string]any)
+	require.True(t, ok)
+	assert.Contains(t, properties, "addressPrefix")
+	assert.Contains(t, properties["provisioningState"], "(ReadOnly)")
+	assert.Contains(t, list.Response, "nextLink")
+}
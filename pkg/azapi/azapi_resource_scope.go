@@ -0,0 +1,127 @@
+package azapi
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ms-henglu/go-azure-types/types"
+)
+
+// ResourceScope tells where a resource type is deployed: the parent_id it takes, the resulting resource ID, and the name and location it needs.
+type ResourceScope struct {
+	// Type is the value of the azapi_resource `type` attribute.
+	Type string `json:"type"`
+	// ParentResourceType is set for child resources, parent_id is then the ID of a resource of this type.
+	ParentResourceType string          `json:"parent_resource_type,omitempty"`
+	Scopes             []ScopeIDFormat `json:"scopes"`
+	Name               NameFormat      `json:"name"`
+	// Location is `required`, `optional` or `not supported`.
+	Location string `json:"location"`
+	// ReadOnly resource types can't be created, only read with the azapi_resource data source.
+	ReadOnly bool `json:"read_only,omitempty"`
+}
+
+// ScopeIDFormat is a scope the resource can be deployed at, with the format of parent_id and the resource ID there.
+type ScopeIDFormat struct {
+	// Scope is `tenant`, `management_group`, `subscription`, `resource_group`, `extension` or `any`.
+	Scope    string `json:"scope"`
+	ParentID string `json:"parent_id"`
+	ID       string `json:"id"`
+	// ReadOnly is true when the resource can only be read at this scope.
+	ReadOnly bool `json:"read_only,omitempty"`
+}
+
+// NameFormat describes the valid values of the `name` attribute.
+type NameFormat struct {
+	// Value is set when the name is fixed, e.g. `default`.
+	Value       string   `json:"value,omitempty"`
+	Constraints []string `json:"constraints,omitempty"`
+}
+
+// scopes are the scope types in the order they are reported, with the format of their IDs.
+var scopes = []struct {
+	scopeType types.ScopeType
+	name      string
+	id        string
+}{
+	{types.Tenant, "tenant", "/"},
+	{types.ManagementGroup, "management_group", "/providers/Microsoft.Management/managementGroups/{managementGroupName}"},
+	{types.Subscription, "subscription", "/subscriptions/{subscriptionId}"},
+	{types.ResourceGroup, "resource_group", "/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}"},
+	{types.Extension, "extension", "{resourceId}"},
+	{types.Unknown, "any", "{scopeId}"},
+}
+
+// GetResourceScope derives the parent_id, resource ID, name and location requirements of a resource type from its scope types and body.
+func GetResourceScope(ctx context.Context, resourceType, apiVersion string) (*ResourceScope, error) {
+	apiType, err := getAzApiType(ctx, resourceType, apiVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get azapi type for resource %s api-version %s: %w", resourceType, apiVersion, err)
+	}
+	// The definition name has the canonical casing, e.g. Microsoft.Network/virtualNetworks/subnets@2024-05-01.
+	canonical, _, _ := strings.Cut(apiType.Name, "@")
+	if canonical == "" {
+		canonical = resourceType
+	}
+	namespace, typeNames, ok := strings.Cut(canonical, "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid resource type %s", canonical)
+	}
+	segments := strings.Split(typeNames, "/")
+	result := &ResourceScope{
+		Type:     canonical + "@" + apiVersion,
+		ReadOnly: apiType.IsReadOnly(),
+	}
+	if len(segments) > 1 {
+		result.ParentResourceType = namespace + "/" + strings.Join(segments[:len(segments)-1], "/")
+	}
+
+	for _, scope := range scopes {
+		if !slices.Contains(apiType.ScopeTypes, scope.scopeType) {
+			continue
+		}
+		format := ScopeIDFormat{
+			Scope:    scope.name,
+			ReadOnly: slices.Contains(apiType.ReadOnlyScopeTypes, scope.scopeType),
+		}
+		prefix := strings.TrimSuffix(scope.id, "/")
+		switch {
+		case strings.EqualFold(canonical, "Microsoft.Resources/resourceGroups"):
+			// Resource groups are the one resource type without a providers segment in their ID.
+			format.ParentID = scope.id
+			format.ID = prefix + "/resourceGroups/{name}"
+		case len(segments) == 1:
+			format.ParentID = scope.id
+			format.ID = fmt.Sprintf("%s/providers/%s/%s/{name}", prefix, namespace, segments[0])
+		default:
+			parentID := fmt.Sprintf("%s/providers/%s", prefix, namespace)
+			for _, segment := range segments[:len(segments)-1] {
+				parentID += fmt.Sprintf("/%s/{%sName}", segment, segment)
+			}
+			format.ParentID = parentID
+			format.ID = fmt.Sprintf("%s/%s/{name}", parentID, segments[len(segments)-1])
+		}
+		result.Scopes = append(result.Scopes, format)
+	}
+
+	body, ok := apiType.Body.Type.(*types.ObjectType)
+	if !ok {
+		return nil, fmt.Errorf("resource %s body is not object", resourceType)
+	}
+	if name, ok := body.Properties["name"]; ok && name.Type != nil {
+		if literal, ok := name.Type.Type.(*types.StringLiteralType); ok {
+			result.Name.Value = literal.Value
+		}
+		result.Name.Constraints = getConstraints(name.Type.Type)
+	}
+	result.Location = "not supported"
+	if location, ok := body.Properties["location"]; ok && !readOnly(location) {
+		result.Location = "optional"
+		if location.IsRequired() {
+			result.Location = "required"
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,73 @@
+package azapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetResourceScope_ResourceGroup(t *testing.T) {
+	scope, err := GetResourceScope(context.Background(), "Microsoft.Network/virtualNetworks", "2024-05-01")
+	require.NoError(t, err)
+	assert.Equal(t, "Microsoft.Network/virtualNetworks@2024-05-01", scope.Type)
+	assert.Empty(t, scope.ParentResourceType)
+	assert.Equal(t, []ScopeIDFormat{{
+		Scope:    "resource_group",
+		ParentID: "/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}",
+		ID:       "/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.Network/virtualNetworks/{name}",
+	}}, scope.Scopes)
+	assert.Equal(t, "optional", scope.Location)
+}
+
+func TestGetResourceScope_ChildResource(t *testing.T) {
+	scope, err := GetResourceScope(context.Background(), "microsoft.storage/storageaccounts/blobservices", "2024-01-01")
+	require.NoError(t, err)
+	assert.Equal(t, "Microsoft.Storage/storageAccounts/blobServices@2024-01-01", scope.Type)
+	assert.Equal(t, "Microsoft.Storage/storageAccounts", scope.ParentResourceType)
+	require.Len(t, scope.Scopes, 1)
+	assert.Equal(t, "/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.Storage/storageAccounts/{storageAccountsName}", scope.Scopes[0].ParentID)
+	assert.Equal(t, "/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.Storage/storageAccounts/{storageAccountsName}/blobServices/{name}", scope.Scopes[0].ID)
+	assert.Equal(t, "default", scope.Name.Value)
+	assert.Equal(t, "not supported", scope.Location)
+}
+
+func TestGetResourceScope_ResourceGroups(t *testing.T) {
+	scope, err := GetResourceScope(context.Background(), "Microsoft.Resources/resourceGroups", "2025-03-01")
+	require.NoError(t, err)
+	assert.Equal(t, []ScopeIDFormat{{
+		Scope:    "subscription",
+		ParentID: "/subscriptions/{subscriptionId}",
+		ID:       "/subscriptions/{subscriptionId}/resourceGroups/{name}",
+	}}, scope.Scopes)
+	assert.Contains(t, scope.Name.Constraints, "Max length: 90")
+	assert.Equal(t, "required", scope.Location)
+}
+
+func TestGetResourceScope_MultipleScopes(t *testing.T) {
+	scope, err := GetResourceScope(context.Background(), "Microsoft.Authorization/policyDefinitions", "2025-01-01")
+	require.NoError(t, err)
+	var names []string
+	for _, s := range scope.Scopes {
+		names = append(names, s.Scope)
+	}
+	assert.Equal(t, []string{"tenant", "management_group", "subscription"}, names)
+	assert.Equal(t, ScopeIDFormat{Scope: "tenant", ParentID: "/", ID: "/providers/Microsoft.Authorization/policyDefinitions/{name}", ReadOnly: true}, scope.Scopes[0])
+	assert.Equal(t, "/providers/Microsoft.Management/managementGroups/{managementGroupName}/providers/Microsoft.Authorization/policyDefinitions/{name}", scope.Scopes[1].ID)
+}
+
+func TestGetResourceScope_AnyScope(t *testing.T) {
+	scope, err := GetResourceScope(context.Background(), "Microsoft.Authorization/roleAssignments", "2022-04-01")
+	require.NoError(t, err)
+	assert.Equal(t, []ScopeIDFormat{{
+		Scope:    "any",
+		ParentID: "{scopeId}",
+		ID:       "{scopeId}/providers/Microsoft.Authorization/roleAssignments/{name}",
+	}}, scope.Scopes)
+}
+
+func TestGetResourceScope_UnknownResourceType(t *testing.T) {
+	_, err := GetResourceScope(context.Background(), "Microsoft.Nope/nopes", "2024-01-01")
+	require.Error(t, err)
+}
@@ -1,25 +1,54 @@
 package azapi
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	tfjson "github.com/hashicorp/terraform-json"
-	"github.com/lonegunmanb/newres/v3/pkg/azapi"
-	azapi_resource "github.com/lonegunmanb/terraform-azapi-schema/v2/generated"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/tracing"
 	"github.com/ms-henglu/go-azure-types/types"
 	"github.com/zclconf/go-cty/cty"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-func GetResourceSchema(resourceType, apiVersion, path string) (string, error) {
-	t, err := getSwaggerResourceType(resourceType, apiVersion)
+func GetResourceSchema(ctx context.Context, resourceType, apiVersion, path string) (string, error) {
+	t, err := GetResourceType(ctx, resourceType, apiVersion, path)
 	if err != nil {
 		return "", err
 	}
-	schema := azapi_resource.Resources["azapi_resource"]
-	schemaType, err := toCtyType(schema.Block)
+	return compactGoType(t.GoString()), nil
+}
+
+// GetResourceType returns the type of the azapi_resource attributes, with the body type of the resource,
+// or the type of the attribute at path, e.g. body.properties.
+func GetResourceType(ctx context.Context, resourceType, apiVersion, path string) (cty.Type, error) {
+	return GetBlockType(ctx, Target{ResourceType: resourceType, ApiVersion: apiVersion}, path)
+}
+
+// GetBlockType returns the type of the attributes of the target block, with the body and output types of the resource,
+// or the type of the attribute at path, e.g. body.properties.
+func GetBlockType(ctx context.Context, target Target, path string) (cty.Type, error) {
+	b, err := getBlock(ctx, target)
 	if err != nil {
-		return "", fmt.Errorf("failed to convert azapi resource schema to cty type: %w", err)
+		return cty.NilType, err
+	}
+	attribute, rest, _ := strings.Cut(path, ".")
+	if t := b.attributeType(attribute); t != nil && rest != "" {
+		// Body properties are looked up in the AzAPI types, a path can go through a recursive type as deep as it likes.
+		property, err := findProperty(t, attribute, rest)
+		if err != nil {
+			return cty.NilType, fmt.Errorf("failed to query type from path %s: %w", path, err)
+		}
+		return b.converter(attribute).convert(property.Type.Type, path), nil
+	}
+	t, err := getSwaggerBlockType(ctx, target.ResourceType, b)
+	if err != nil {
+		return cty.NilType, err
+	}
+	schemaType, err := toCtyType(b.schema)
+	if err != nil {
+		return cty.NilType, fmt.Errorf("failed to convert azapi resource schema to cty type: %w", err)
 	}
 	attributeTypes := schemaType.AttributeTypes()
 	for n, at := range t.AttributeTypes() {
@@ -28,33 +57,49 @@ func GetResourceSchema(resourceType, apiVersion, path string) (string, error) {
 	mergedType := cty.Object(attributeTypes)
 
 	if path == "" {
-		return compactGoType(mergedType.GoString()), nil
+		return mergedType, nil
 	}
 	subType, err := queryTypeFromType(mergedType, path)
 	if err != nil {
-		return "", fmt.Errorf("failed to query type from path %s: %w", path, err)
+		return cty.NilType, fmt.Errorf("failed to query type from path %s: %w", path, err)
 	}
-	return compactGoType(subType.GoString()), nil
+	return subType, nil
 }
 
-func getSwaggerResourceType(resourceType, apiVersion string) (cty.Type, error) {
-	apiType, err := azapi.GetAzApiType(resourceType, apiVersion)
+func getSwaggerResourceType(ctx context.Context, resourceType, apiVersion string) (cty.Type, error) {
+	b, err := getBlock(ctx, Target{ResourceType: resourceType, ApiVersion: apiVersion})
 	if err != nil {
-		return cty.NilType, fmt.Errorf("failed to get azapi type for resource %s api-version %s: %w", resourceType, apiVersion, err)
+		return cty.NilType, err
 	}
-	bodyType, ok := apiType.Body.Type.(*types.ObjectType)
-	if !ok {
-		return cty.NilType, fmt.Errorf("resource body type is not an object type")
+	return getSwaggerBlockType(ctx, resourceType, b)
+}
+
+// getSwaggerBlockType converts the body and output of a block to the attributes they set.
+func getSwaggerBlockType(ctx context.Context, resourceType string, b *block) (_ cty.Type, err error) {
+	_, span := tracing.Start(ctx, "azapi.convert_to_cty", attribute.String("azapi.resource_type", resourceType))
+	defer func() { tracing.End(span, err) }()
+	attributes := make(map[string]cty.Type)
+	if b.body != nil {
+		for n, at := range b.converter("body").bodyAttributes(b) {
+			attributes[n] = at
+		}
+	}
+	if b.output != nil {
+		attributes["output"] = b.converter("output").convert(b.output, "output")
 	}
-	blockSchema, err := azapi.ConvertAzApiObjectTypeToTerraformJsonSchemaAttribute(types.ObjectProperty{
-		Type: &types.TypeReference{
-			Type: bodyType,
-		},
-	})
+	return cty.Object(attributes), nil
+}
+
+func getBodyType(ctx context.Context, resourceType, apiVersion string) (*types.ObjectType, error) {
+	apiType, err := getAzApiType(ctx, resourceType, apiVersion)
 	if err != nil {
-		return cty.NilType, fmt.Errorf("failed to convert az api object type to terraform json schema: %w", err)
+		return nil, fmt.Errorf("failed to get azapi type for resource %s api-version %s: %w", resourceType, apiVersion, err)
+	}
+	bodyType, ok := apiType.Body.Type.(*types.ObjectType)
+	if !ok {
+		return nil, fmt.Errorf("resource body type is not an object type")
 	}
-	return toCtyType(blockSchema)
+	return bodyType, nil
 }
 
 func compactGoType(goType string) string {
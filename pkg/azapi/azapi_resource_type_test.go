@@ -1,6 +1,7 @@
 package azapi
 
 import (
+	"context"
 	"github.com/zclconf/go-cty/cty"
 	"strings"
 	"testing"
@@ -10,7 +11,7 @@ import (
 )
 
 func TestGetAzAPIType_WithoutJsonPath(t *testing.T) {
-	resourceType, err := getSwaggerResourceType("Microsoft.Resources/resourcegroups", "2024-07-01")
+	resourceType, err := getSwaggerResourceType(context.Background(), "Microsoft.Resources/resourcegroups", "2024-07-01")
 	require.NoError(t, err)
 	require.True(t, resourceType.IsObjectType())
 	require.True(t, resourceType.HasAttribute("location"))
@@ -59,7 +60,7 @@ func TestGetAzAPIType_WithPath(t *testing.T) {
 			caseName = strings.Join([]string{c.resourceType, c.apiVersion, c.path}, "-")
 		}
 		t.Run(caseName, func(t *testing.T) {
-			schema, err := GetResourceSchema(c.resourceType, c.apiVersion, c.path)
+			schema, err := GetResourceSchema(context.Background(), c.resourceType, c.apiVersion, c.path)
 			require.NoError(t, err)
 			assert.Equal(t, c.expectedType, schema)
 		})
@@ -87,7 +88,7 @@ func TestGetAzAPIType_WithPathToAzAPIProperty(t *testing.T) {
 			caseName = strings.Join([]string{c.resourceType, c.apiVersion, c.path}, "-")
 		}
 		t.Run(caseName, func(t *testing.T) {
-			schema, err := GetResourceSchema(c.resourceType, c.apiVersion, c.path)
+			schema, err := GetResourceSchema(context.Background(), c.resourceType, c.apiVersion, c.path)
 			require.NoError(t, err)
 			assert.Equal(t, c.expectedType, schema)
 		})
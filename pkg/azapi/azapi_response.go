@@ -0,0 +1,245 @@
+package azapi
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/ms-henglu/go-azure-types/types"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	// maxExportPaths and maxExportQueries cap the suggested response_export_values, the shallowest are kept.
+	maxExportPaths   = 20
+	maxExportQueries = 10
+)
+
+// identifier matches the property names a JMESPath expression can use without quoting.
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ResponseExports are response_export_values suggested for the read-only properties of a response,
+// Paths are for the list form and Queries, named JMESPath expressions, for the map form.
+type ResponseExports struct {
+	Paths   []string
+	Queries map[string]string
+}
+
+// GetResponseType returns the type of the azapi_resource `output` attribute, which is the GET response body with the read-only
+// properties and without the write-only ones, or the type of the property at path, e.g. output.properties.
+func GetResponseType(ctx context.Context, resourceType, apiVersion, path string) (cty.Type, error) {
+	property, path, err := responseProperty(ctx, resourceType, apiVersion, path)
+	if err != nil {
+		return cty.NilType, err
+	}
+	c := newTypeConverter()
+	c.response = true
+	return c.convert(property.Type.Type, path), nil
+}
+
+// GetResponseDescription describes the GET response body like GetResourceSchemaDescription describes the resource body.
+func GetResponseDescription(ctx context.Context, resourceType, apiVersion, path string) (any, error) {
+	property, path, err := responseProperty(ctx, resourceType, apiVersion, path)
+	if err != nil {
+		return nil, err
+	}
+	return convertPropertyToMap(property, path, make(map[types.TypeBase]string))
+}
+
+// GetResponseOutline outlines the GET response body like GetResourceOutline outlines the resource.
+func GetResponseOutline(ctx context.Context, resourceType, apiVersion, path string, depth int) (*OutlineProperty, error) {
+	if err := validateResponsePath(path); err != nil {
+		return nil, err
+	}
+	if path == "" {
+		path = "output"
+	}
+	bodyType, err := getBodyType(ctx, resourceType, apiVersion)
+	if err != nil {
+		return nil, err
+	}
+	return outlineAt(bodyType, "output", path, depth)
+}
+
+// GetResponseExports suggests response_export_values for the read-only properties of the GET response.
+func GetResponseExports(ctx context.Context, resourceType, apiVersion string) (*ResponseExports, error) {
+	bodyType, err := getBodyType(ctx, resourceType, apiVersion)
+	if err != nil {
+		return nil, err
+	}
+	type export struct {
+		path  string
+		depth int
+		query bool
+		key   bool
+	}
+	var exports []export
+	ancestors := make(map[types.TypeBase]bool)
+	var walk func(t types.TypeBase, path string, depth int, query, readOnly bool)
+	walk = func(t types.TypeBase, path string, depth int, query, readOnly bool) {
+		var properties map[string]types.ObjectProperty
+		switch t := t.(type) {
+		case *types.ObjectType:
+			if len(t.Properties) == 0 && t.AdditionalProperties != nil {
+				break
+			}
+			properties = t.Properties
+		case *types.DiscriminatedObjectType:
+			properties = t.BaseProperties
+		case *types.ArrayType:
+			if t.ItemType != nil && propertiesOwner(t.ItemType.Type) != nil {
+				walk(t.ItemType.Type, path+"[]", depth, true, readOnly)
+				return
+			}
+		}
+		if properties == nil {
+			// The IDs and names of the objects in an array are worth exporting even when they can be set, e.g. the subnet IDs of a virtual network.
+			name := path[strings.LastIndex(path, ".")+1:]
+			key := query && (name == "id" || name == "name")
+			if path != "" && (readOnly || key) {
+				exports = append(exports, export{path: path, depth: depth, query: query, key: key})
+			}
+			return
+		}
+		if ancestors[t] {
+			return
+		}
+		ancestors[t] = true
+		defer delete(ancestors, t)
+		for name, p := range properties {
+			if p.Type == nil || !identifier.MatchString(name) || slices.Contains(p.Flags, types.WriteOnly) {
+				continue
+			}
+			if path == "" && name == "apiVersion" {
+				// The API version is part of the resource type, it's not in the response.
+				continue
+			}
+			walk(p.Type.Type, joinPath(path, name), depth+1, query, readOnly || slices.Contains(p.Flags, types.ReadOnly))
+		}
+	}
+	walk(bodyType, "", 0, false, false)
+	slices.SortFunc(exports, func(a, b export) int {
+		if a.key != b.key {
+			if a.key {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Or(cmp.Compare(a.depth, b.depth), cmp.Compare(a.path, b.path))
+	})
+
+	result := &ResponseExports{Queries: make(map[string]string)}
+	for _, e := range exports {
+		switch {
+		case !e.query && len(result.Paths) < maxExportPaths:
+			result.Paths = append(result.Paths, e.path)
+		case e.query && len(result.Queries) < maxExportQueries:
+			result.Queries[uniqueExportName(result.Queries, e.path)] = e.path
+		}
+	}
+	return result, nil
+}
+
+// RenderResponseExports renders the suggestions as response_export_values in HCL.
+func RenderResponseExports(exports *ResponseExports) string {
+	var sb strings.Builder
+	if len(exports.Paths) > 0 {
+		sb.WriteString("Export response values by path, they're read as `output.<path>`, e.g. `azapi_resource.this.output." + exports.Paths[0] + "`:\n")
+		quoted := make([]string, 0, len(exports.Paths))
+		for _, p := range exports.Paths {
+			quoted = append(quoted, fmt.Sprintf("%q", p))
+		}
+		fmt.Fprintf(&sb, "response_export_values = [%s]\n", strings.Join(quoted, ", "))
+	}
+	if len(exports.Queries) > 0 {
+		names := make([]string, 0, len(exports.Queries))
+		for name := range exports.Queries {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		fmt.Fprintf(&sb, "Or name the values with JMESPath queries, they're read as `output.<name>`, e.g. `azapi_resource.this.output.%s`:\n", names[0])
+		sb.WriteString("response_export_values = {\n")
+		for _, name := range names {
+			fmt.Fprintf(&sb, "  %s = %q\n", name, exports.Queries[name])
+		}
+		sb.WriteString("}\n")
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// uniqueExportName returns the name of a JMESPath query that no other query of queries has.
+// A name already taken falls back to the name of the full path, then to that name suffixed with a number.
+func uniqueExportName(queries map[string]string, path string) string {
+	name := exportName(path, false)
+	if _, taken := queries[name]; !taken {
+		return name
+	}
+	full := exportName(path, true)
+	name = full
+	for i := 2; ; i++ {
+		if _, taken := queries[name]; !taken {
+			return name
+		}
+		name = fmt.Sprintf("%s_%d", full, i)
+	}
+}
+
+// exportName names a JMESPath query after its path in snake case, without the `properties` segments unless full is set,
+// e.g. properties.subnets[].id is subnets_id.
+func exportName(path string, full bool) string {
+	var words []string
+	for _, segment := range strings.Split(strings.ReplaceAll(path, "[]", ""), ".") {
+		if full || segment != "properties" {
+			words = append(words, snakeCase(segment))
+		}
+	}
+	return strings.Join(words, "_")
+}
+
+func snakeCase(s string) string {
+	runes := []rune(s)
+	var sb strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			previous := runes[i-1]
+			next := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && next) {
+				sb.WriteRune('_')
+			}
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String()
+}
+
+// responseProperty returns the property at path in the GET response, the path is empty or starts with output.
+// The path of the property is returned too, an empty path is output.
+func responseProperty(ctx context.Context, resourceType, apiVersion, path string) (types.ObjectProperty, string, error) {
+	if err := validateResponsePath(path); err != nil {
+		return types.ObjectProperty{}, "", err
+	}
+	bodyType, err := getBodyType(ctx, resourceType, apiVersion)
+	if err != nil {
+		return types.ObjectProperty{}, "", err
+	}
+	bodyPath, ok := strings.CutPrefix(path, "output.")
+	if !ok {
+		return types.ObjectProperty{Type: &types.TypeReference{Type: bodyType}}, "output", nil
+	}
+	property, err := findProperty(bodyType, "output", bodyPath)
+	if err != nil {
+		return types.ObjectProperty{}, "", fmt.Errorf("failed to query type from path %s: %w", path, err)
+	}
+	return property, path, nil
+}
+
+func validateResponsePath(path string) error {
+	if path != "" && path != "output" && !strings.HasPrefix(path, "output.") {
+		return fmt.Errorf("invalid path '%s', response paths start with output, e.g. output.properties", path)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package azapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestGetResponseType_HasReadOnlyProperties(t *testing.T) {
+	output, err := GetResponseType(context.Background(), "Microsoft.ContainerRegistry/registries", "2023-07-01", "")
+	require.NoError(t, err)
+	assert.True(t, output.HasAttribute("id"))
+	properties := output.AttributeType("properties")
+	assert.Equal(t, cty.String, properties.AttributeType("loginServer"))
+
+	body, err := GetResourceType(context.Background(), "Microsoft.ContainerRegistry/registries", "2023-07-01", "body.properties")
+	require.NoError(t, err)
+	assert.False(t, body.HasAttribute("loginServer"))
+}
+
+func TestGetResponseType_LeavesOutWriteOnlyProperties(t *testing.T) {
+	properties, err := GetResponseType(context.Background(), "Microsoft.Sql/servers", "2023-08-01-preview", "output.properties")
+	require.NoError(t, err)
+	assert.True(t, properties.HasAttribute("administratorLogin"))
+	assert.False(t, properties.HasAttribute("administratorLoginPassword"))
+}
+
+func TestGetResponseType_InvalidPath(t *testing.T) {
+	_, err := GetResponseType(context.Background(), "Microsoft.ContainerRegistry/registries", "2023-07-01", "body.properties")
+	assert.ErrorContains(t, err, "response paths start with output")
+}
+
+func TestGetResponseDescription(t *testing.T) {
+	description, err := GetResponseDescription(context.Background(), "Microsoft.ContainerRegistry/registries", "2023-07-01", "output.properties.loginServer")
+	require.NoError(t, err)
+	assert.Contains(t, description, "(ReadOnly)")
+}
+
+func TestGetResponseOutline(t *testing.T) {
+	outline, err := GetResponseOutline(context.Background(), "Microsoft.ContainerRegistry/registries", "2023-07-01", "", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "output", outline.Name)
+	text, collapsed := RenderOutline(outline, false)
+	assert.Contains(t, text, "  id: string (ReadOnly, DeployTimeConstant)")
+	assert.Contains(t, collapsed, "output.properties")
+}
+
+func TestGetResponseExports(t *testing.T) {
+	exports, err := GetResponseExports(context.Background(), "Microsoft.Network/virtualNetworks", "2024-05-01")
+	require.NoError(t, err)
+	assert.Contains(t, exports.Paths, "id")
+	assert.Contains(t, exports.Paths, "properties.provisioningState")
+	assert.NotContains(t, exports.Paths, "apiVersion")
+	assert.Equal(t, "properties.subnets[].id", exports.Queries["subnets_id"])
+
+	text := RenderResponseExports(exports)
+	assert.Contains(t, text, `response_export_values = ["etag", "id"`)
+	assert.Contains(t, text, `  subnets_id = "properties.subnets[].id"`)
+}
+
+func TestUniqueExportName_Collisions(t *testing.T) {
+	queries := map[string]string{}
+	for _, path := range []string{"subnets[].id", "properties.subnets[].id", "properties.subnets[].properties.id"} {
+		queries[uniqueExportName(queries, path)] = path
+	}
+	assert.Equal(t, map[string]string{
+		"subnets_id":                       "subnets[].id",
+		"properties_subnets_id":            "properties.subnets[].id",
+		"properties_subnets_properties_id": "properties.subnets[].properties.id",
+	}, queries)
+
+	queries = map[string]string{"subnets_id": "properties.subnets[].id"}
+	assert.Equal(t, "subnets_id_2", uniqueExportName(queries, "subnets[].id"))
+}
+
+func TestSnakeCase(t *testing.T) {
+	assert.Equal(t, "private_ip_address", snakeCase("privateIPAddress"))
+	assert.Equal(t, "ip_configurations", snakeCase("ipConfigurations"))
+	assert.Equal(t, "id", snakeCase("id"))
+}
@@ -1,64 +1,47 @@
 package azapi
 
 import (
+	"context"
 	"fmt"
 	tfjson "github.com/hashicorp/terraform-json"
-	"github.com/lonegunmanb/newres/v3/pkg/azapi"
-	azapi_resource "github.com/lonegunmanb/terraform-azapi-schema/v2/generated"
 	"github.com/ms-henglu/go-azure-types/types"
 	"strings"
 )
 
-func GetResourceSchemaDescription(resourceType, apiVersion, path string) (any, error) {
-	// Get swagger resource descriptions
-	swaggerDescriptions, err := getSwaggerResourceDescriptions(resourceType, apiVersion)
-	if err != nil {
-		return nil, err
-	}
+func GetResourceSchemaDescription(ctx context.Context, resourceType, apiVersion, path string) (any, error) {
+	return GetBlockDescription(ctx, Target{ResourceType: resourceType, ApiVersion: apiVersion}, path)
+}
 
-	// Get azapi resource schema descriptions
-	azapiDescriptions, err := getAzapiResourceDescriptions()
+// GetBlockDescription describes the attributes of the target block, with the body and output of the resource, or the attribute at path.
+func GetBlockDescription(ctx context.Context, target Target, path string) (any, error) {
+	b, err := getBlock(ctx, target)
 	if err != nil {
 		return nil, err
 	}
-
-	// Merge descriptions
-	mergedDescriptions := azapiDescriptions
-	for k, v := range swaggerDescriptions {
-		mergedDescriptions[k] = v
-	}
-
-	if path == "" {
-		return mergedDescriptions, nil
+	attribute, rest, _ := strings.Cut(path, ".")
+	if t := b.attributeType(attribute); t != nil && rest != "" {
+		// Body properties are looked up in the AzAPI types, a path can go through a recursive type as deep as it likes.
+		property, err := findProperty(t, attribute, rest)
+		if err != nil {
+			return nil, err
+		}
+		return convertPropertyToMap(property, path, make(map[types.TypeBase]string))
 	}
-	return queryDescriptionInObject(mergedDescriptions, path)
-}
 
-func getSwaggerResourceDescriptions(resourceType, apiVersion string) (map[string]any, error) {
-	apiType, err := azapi.GetAzApiType(resourceType, apiVersion)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get azapi type for resource %s api-version %s: %w", resourceType, apiVersion, err)
-	}
-	bodyType, ok := apiType.Body.Type.(*types.ObjectType)
-	if !ok {
-		return nil, fmt.Errorf("resource body type is not an object type")
-	}
-	result := make(map[string]any)
-	for n, p := range bodyType.Properties {
-		desc, err := ConvertAzApiObjectPropertyToMap(p)
-		if err != nil {
-			return nil, fmt.Errorf("failed to convert property %s: %w", n, err)
+	// Merge the swagger descriptions of body and output into the azapi block descriptions
+	descriptions := convertSchemaBlockToDescriptionMap(b.schema)
+	for _, attribute := range []string{"body", "output"} {
+		if t := b.attributeType(attribute); t != nil {
+			if descriptions[attribute], err = describeType(t, attribute); err != nil {
+				return nil, err
+			}
 		}
-		result[n] = desc
 	}
-	return map[string]any{
-		"body": result,
-	}, nil
-}
 
-func getAzapiResourceDescriptions() (map[string]any, error) {
-	schema := azapi_resource.Resources["azapi_resource"]
-	return convertSchemaBlockToDescriptionMap(schema.Block), nil
+	if path == "" {
+		return descriptions, nil
+	}
+	return queryDescriptionInObject(descriptions, path)
 }
 
 func convertSchemaBlockToDescriptionMap(block *tfjson.SchemaBlock) map[string]any {
@@ -130,6 +113,16 @@ func queryDescriptionInObject(result map[string]any, path string) (any, error) {
 // ConvertAzApiObjectPropertyToMap converts types.ObjectProperty to map[string]any
 // where values are property descriptions, or nested maps for object properties
 func ConvertAzApiObjectPropertyToMap(property types.ObjectProperty) (any, error) {
+	return convertPropertyToMap(property, "", make(map[types.TypeBase]string))
+}
+
+// convertPropertyToMap converts the property at path, ancestors are the objects being converted with their path.
+// An object nested in itself is converted to {"$ref": path of its outer occurrence},
+// the constraints of an object, such as Sensitive, are its "$constraints" entry.
+func convertPropertyToMap(property types.ObjectProperty, path string, ancestors map[types.TypeBase]string) (any, error) {
+	if d, ok := property.Type.Type.(*types.DiscriminatedObjectType); ok {
+		return convertDiscriminatedObjectTypeToMap(d, path, ancestors)
+	}
 	objType, ok := property.Type.Type.(*types.ObjectType)
 	if !ok {
 		// If it's not an object type, return a simple map with description
@@ -156,11 +149,24 @@ func ConvertAzApiObjectPropertyToMap(property types.ObjectProperty) (any, error)
 		if possibleValues := getPossibleValues(property); len(possibleValues) > 0 {
 			description += fmt.Sprintf(" (Possible values: %s)", strings.Join(possibleValues, ","))
 		}
+		for _, constraint := range getConstraints(property.Type.Type) {
+			description += fmt.Sprintf(" (%s)", constraint)
+		}
 
 		return description, nil
 	}
 
-	return convertObjectTypeToMap(objType)
+	if ref, ok := ancestors[objType]; ok {
+		return map[string]any{"$ref": ref}, nil
+	}
+	result, err := convertObjectTypeToMap(objType, path, ancestors)
+	if err != nil {
+		return nil, err
+	}
+	if constraints := getConstraints(objType); len(constraints) > 0 {
+		result["$constraints"] = strings.Join(constraints, ", ")
+	}
+	return result, nil
 }
 
 func getPossibleValues(property types.ObjectProperty) []string {
@@ -176,12 +182,46 @@ func getPossibleValues(property types.ObjectProperty) []string {
 	return nil
 }
 
+// getConstraints returns the value constraints Azure validates, e.g. `Max length: 24`, and whether the value is sensitive.
+func getConstraints(t types.TypeBase) []string {
+	var constraints []string
+	add := func(name string, value *int) {
+		if value != nil {
+			constraints = append(constraints, fmt.Sprintf("%s: %d", name, *value))
+		}
+	}
+	switch t := t.(type) {
+	case *types.StringType:
+		add("Min length", t.MinLength)
+		add("Max length", t.MaxLength)
+		if t.Pattern != "" {
+			constraints = append(constraints, "Pattern: "+t.Pattern)
+		}
+		if t.Sensitive {
+			constraints = append(constraints, "Sensitive")
+		}
+	case *types.IntegerType:
+		add("Min value", t.MinValue)
+		add("Max value", t.MaxValue)
+	case *types.ArrayType:
+		add("Min items", t.MinLength)
+		add("Max items", t.MaxLength)
+	case *types.ObjectType:
+		if t.Sensitive {
+			constraints = append(constraints, "Sensitive")
+		}
+	}
+	return constraints
+}
+
 // convertObjectTypeToMap converts an ObjectType to map[string]any recursively
-func convertObjectTypeToMap(objType *types.ObjectType) (map[string]any, error) {
+func convertObjectTypeToMap(objType *types.ObjectType, path string, ancestors map[types.TypeBase]string) (map[string]any, error) {
 	result := make(map[string]any)
+	ancestors[objType] = path
+	defer delete(ancestors, objType)
 
 	for name, prop := range objType.Properties {
-		descs, err := ConvertAzApiObjectPropertyToMap(prop)
+		descs, err := convertPropertyToMap(prop, joinPath(path, name), ancestors)
 		if err != nil {
 			return nil, err
 		}
@@ -190,3 +230,24 @@ func convertObjectTypeToMap(objType *types.ObjectType) (map[string]any, error) {
 
 	return result, nil
 }
+
+// convertDiscriminatedObjectTypeToMap converts the base properties of a discriminated object,
+// the discriminator is described with the possible values and how to query a variant.
+func convertDiscriminatedObjectTypeToMap(objType *types.DiscriminatedObjectType, path string, ancestors map[types.TypeBase]string) (map[string]any, error) {
+	if ref, ok := ancestors[objType]; ok {
+		return map[string]any{"$ref": ref}, nil
+	}
+	ancestors[objType] = path
+	defer delete(ancestors, objType)
+	result, err := convertObjectTypeToMap(&types.ObjectType{Properties: objType.BaseProperties}, path, ancestors)
+	if err != nil {
+		return nil, err
+	}
+	values := variantValues(objType)
+	example := "<value>"
+	if len(values) > 0 {
+		example = values[0]
+	}
+	result[objType.Discriminator] = fmt.Sprintf("Discriminator of the variants, query the properties of a variant with a path like %s[%s=%s] (Required) (Possible values: %s)", path, objType.Discriminator, example, strings.Join(values, ","))
+	return result, nil
+}
@@ -1,13 +1,17 @@
 package azapi
 
 import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ms-henglu/go-azure-types/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"testing"
 )
 
 func TestQueryAzapiSchemaDesc_EnumAsPossibleValues(t *testing.T) {
-	m, err := GetResourceSchemaDescription("Microsoft.CognitiveServices/accounts", "2025-06-01", "")
+	m, err := GetResourceSchemaDescription(context.Background(), "Microsoft.CognitiveServices/accounts", "2025-06-01", "")
 	require.NoError(t, err)
 	descriptions, ok := m.(map[string]any)
 	require.True(t, ok)
@@ -22,7 +26,7 @@ func TestQueryAzapiSchemaDesc_EnumAsPossibleValues(t *testing.T) {
 }
 
 func TestQueryAzapiSchemaDesc_WithPathToProperty(t *testing.T) {
-	descriptions, err := GetResourceSchemaDescription("Microsoft.CognitiveServices/accounts", "2025-06-01", "body.properties.publicNetworkAccess")
+	descriptions, err := GetResourceSchemaDescription(context.Background(), "Microsoft.CognitiveServices/accounts", "2025-06-01", "body.properties.publicNetworkAccess")
 	require.NoError(t, err)
 	desc, ok := descriptions.(string)
 	require.True(t, ok)
@@ -30,7 +34,7 @@ func TestQueryAzapiSchemaDesc_WithPathToProperty(t *testing.T) {
 }
 
 func TestQueryAzapiSchemaDesc_WithPathToObject(t *testing.T) {
-	descriptions, err := GetResourceSchemaDescription("Microsoft.CognitiveServices/accounts", "2025-06-01", "body.properties.encryption")
+	descriptions, err := GetResourceSchemaDescription(context.Background(), "Microsoft.CognitiveServices/accounts", "2025-06-01", "body.properties.encryption")
 	require.NoError(t, err)
 	desc, ok := descriptions.(map[string]any)
 	require.True(t, ok)
@@ -44,7 +48,7 @@ func TestQueryAzapiSchemaDesc_WithPathToObject(t *testing.T) {
 }
 
 func TestQueryAzapiSchemaDesc_Readonly(t *testing.T) {
-	dateCreated, err := GetResourceSchemaDescription("Microsoft.CognitiveServices/accounts", "2025-06-01", "body.properties.dateCreated")
+	dateCreated, err := GetResourceSchemaDescription(context.Background(), "Microsoft.CognitiveServices/accounts", "2025-06-01", "body.properties.dateCreated")
 	require.NoError(t, err)
 	desc, ok := dateCreated.(string)
 	require.True(t, ok)
@@ -52,9 +56,42 @@ func TestQueryAzapiSchemaDesc_Readonly(t *testing.T) {
 }
 
 func TestQueryAzapiSchemaDesc_NonApiAttribute_RetryErrorMessageRegex(t *testing.T) {
-	description, err := GetResourceSchemaDescription("Microsoft.CognitiveServices/accounts", "2025-06-01", "retry.error_message_regex")
+	description, err := GetResourceSchemaDescription(context.Background(), "Microsoft.CognitiveServices/accounts", "2025-06-01", "retry.error_message_regex")
 	require.NoError(t, err)
 	desc, ok := description.(string)
 	require.True(t, ok)
 	assert.Equal(t, "A list of regular expressions to match against error messages. If any of the regular expressions match, the request will be retried.", desc)
 }
+
+func TestQueryAzapiSchemaDesc_StringConstraints(t *testing.T) {
+	description, err := GetResourceSchemaDescription(context.Background(), "Microsoft.Storage/storageAccounts", "2023-05-01", "body.name")
+	require.NoError(t, err)
+	assert.Equal(t, "The resource name (Required) (DeployTimeConstant) (Min length: 3) (Max length: 24) (Pattern: ^[a-z0-9]+$)", description)
+}
+
+func TestQueryAzapiSchemaDesc_Sensitive(t *testing.T) {
+	description, err := GetResourceSchemaDescription(context.Background(), "Microsoft.Compute/virtualMachines", "2024-11-01", "body.properties.osProfile.adminPassword")
+	require.NoError(t, err)
+	desc, ok := description.(string)
+	require.True(t, ok)
+	assert.True(t, strings.HasSuffix(desc, " (Sensitive)"))
+}
+
+func TestQueryAzapiSchemaDesc_SensitiveObject(t *testing.T) {
+	description, err := GetResourceSchemaDescription(context.Background(), "Microsoft.Datadog/monitors", "2023-10-20", "body.properties.userInfo")
+	require.NoError(t, err)
+	desc, ok := description.(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "Sensitive", desc["$constraints"])
+	assert.Contains(t, desc, "emailAddress")
+}
+
+func TestGetConstraints(t *testing.T) {
+	one, ten := 1, 10
+	assert.Equal(t, []string{"Min value: 1", "Max value: 10"}, getConstraints(&types.IntegerType{MinValue: &one, MaxValue: &ten}))
+	assert.Equal(t, []string{"Min items: 1", "Max items: 10"}, getConstraints(&types.ArrayType{MinLength: &one, MaxLength: &ten}))
+	assert.Equal(t, []string{"Max length: 10", "Pattern: ^[a-z]+$", "Sensitive"}, getConstraints(&types.StringType{MaxLength: &ten, Pattern: "^[a-z]+$", Sensitive: true}))
+	assert.Equal(t, []string{"Sensitive"}, getConstraints(&types.ObjectType{Sensitive: true}))
+	assert.Empty(t, getConstraints(&types.StringType{}))
+	assert.Empty(t, getConstraints(&types.BooleanType{}))
+}
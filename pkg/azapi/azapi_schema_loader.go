@@ -0,0 +1,52 @@
+package azapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/metrics"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/tracing"
+	"github.com/ms-henglu/go-azure-types/types"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// schemaLoader is shared so the embedded type index is only parsed once, types.DefaultAzureSchemaLoader returns a fresh loader on every call.
+var schemaLoader = sync.OnceValue(types.DefaultAzureSchemaLoader)
+
+// loadedDefinitions tracks the resource definitions the shared loader already holds, keyed by lower case `type@api-version`.
+var loadedDefinitions sync.Map
+
+// LoadSchemaIndex parses the embedded AzAPI type index, so the first query doesn't pay for it.
+func LoadSchemaIndex() error {
+	if schemaLoader().GetSchema() == nil {
+		return fmt.Errorf("failed to load the embedded azapi type index")
+	}
+	return nil
+}
+
+// getAzApiType loads the definition of a resource type from the shared loader, which caches loaded definitions.
+func getAzApiType(ctx context.Context, resourceType, apiVersion string) (_ *types.ResourceType, err error) {
+	key := strings.ToLower(resourceType + "@" + apiVersion)
+	_, hit := loadedDefinitions.Load(key)
+	metrics.ObserveCache(metrics.CacheAzAPITypes, hit)
+	_, span := tracing.Start(ctx, "azapi.load_type",
+		attribute.String("azapi.resource_type", resourceType),
+		attribute.String("azapi.api_version", apiVersion),
+		attribute.Bool("cache.hit", hit),
+	)
+	defer func() { tracing.End(span, err) }()
+	resourceDef, err := schemaLoader().GetResourceDefinition(resourceType, apiVersion)
+	if err != nil {
+		return nil, err
+	}
+	if resourceDef == nil || resourceDef.Body == nil {
+		return nil, fmt.Errorf("resource %s not found", resourceType)
+	}
+	if _, ok := resourceDef.Body.Type.(*types.ObjectType); !ok {
+		return nil, fmt.Errorf("resource %s body is not object", resourceType)
+	}
+	loadedDefinitions.Store(key, struct{}{})
+	return resourceDef, nil
+}
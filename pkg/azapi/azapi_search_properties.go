@@ -0,0 +1,269 @@
+package azapi
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// snippetRadius is the number of characters a snippet shows around the first match in a description.
+	snippetRadius = 80
+	// Matches in the property name and in its possible values weigh more than in the description.
+	nameWeight  = 3.0
+	valueWeight = 2.0
+	// minStemLength is the shortest stem the endings of stemEndings are taken off.
+	minStemLength = 2
+)
+
+// stemEndings are the groups of endings that make words of the same stem, e.g. policy and policies or redundant and redundancy.
+var stemEndings = [][]string{
+	{"", "s", "es"},
+	{"y", "ies"},
+	{"ant", "ance", "ancy"},
+	{"ent", "ence", "ency"},
+}
+
+// PropertySearchResult is a property found by SearchProperties, with the part of its description that matched.
+type PropertySearchResult struct {
+	// Type is the resource type with its latest api-version, e.g. Microsoft.Storage/storageAccounts@2023-05-01.
+	Type    string `json:"type"`
+	Path    string `json:"path"`
+	Snippet string `json:"snippet,omitempty"`
+}
+
+// searchDocument is a property being ranked by SearchProperties.
+type searchDocument struct {
+	resourceType string
+	property     indexedProperty
+	name         []string
+	values       []string
+	description  []string
+	score        float64
+}
+
+// SearchProperties searches the descriptions, names and possible values of the body properties of the resource types at their latest api-version.
+// resourceType selects the resource types to search like selectResourceTypes, e.g. Microsoft.Storage/storageAccounts or Microsoft.Storage/.
+// The results are ranked by relevance: the rarer the matched words and the more of them, the better; a match in the property name ranks best.
+func SearchProperties(ctx context.Context, resourceType, text string) ([]PropertySearchResult, error) {
+	terms := slices.Compact(slices.Sorted(slices.Values(words(text))))
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("search text has no words")
+	}
+	resourceTypes, err := selectResourceTypes(resourceType)
+	if err != nil {
+		return nil, err
+	}
+	var documents []*searchDocument
+	for _, t := range resourceTypes {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		apiVersion, properties, err := latestPropertyIndex(ctx, t)
+		if err != nil {
+			continue
+		}
+		for _, p := range properties {
+			segments := strings.Split(p.key, ".")
+			name := p.path[strings.LastIndex(p.path, ".")+1:]
+			documents = append(documents, &searchDocument{
+				resourceType: t + "@" + apiVersion,
+				property:     p,
+				name:         words(name),
+				values:       words(strings.Join(p.values, " ")),
+				description:  words(p.description),
+				score:        -0.1 * float64(len(segments)),
+			})
+		}
+	}
+
+	// Rare words tell more about a property than common ones.
+	idf := make(map[string]float64, len(terms))
+	for _, term := range terms {
+		count := 0
+		for _, d := range documents {
+			if matchCount(d.name, term)+matchCount(d.values, term)+matchCount(d.description, term) > 0 {
+				count++
+			}
+		}
+		idf[term] = math.Log(1 + float64(len(documents))/float64(count+1))
+	}
+	phrase := strings.Join(words(text), " ")
+	var results []*searchDocument
+	for _, d := range documents {
+		matched := 0
+		score := 0.0
+		for _, term := range terms {
+			weight := nameWeight*float64(min(matchCount(d.name, term), 1)) +
+				valueWeight*float64(min(matchCount(d.values, term), 1)) +
+				logFrequency(matchCount(d.description, term))
+			if weight > 0 {
+				matched++
+				score += idf[term] * weight
+			}
+		}
+		if matched == 0 {
+			continue
+		}
+		if len(terms) > 1 && strings.Contains(strings.Join(d.description, " "), phrase) {
+			score *= 1.5
+		}
+		// Properties matching more of the words rank first, whatever the weight of each match.
+		d.score += score * float64(matched*matched)
+		results = append(results, d)
+	}
+	slices.SortStableFunc(results, func(a, b *searchDocument) int {
+		return cmp.Or(cmp.Compare(b.score, a.score), cmp.Compare(a.resourceType, b.resourceType), cmp.Compare(a.property.path, b.property.path))
+	})
+
+	found := make([]PropertySearchResult, 0, len(results))
+	for _, d := range results {
+		found = append(found, PropertySearchResult{
+			Type:    d.resourceType,
+			Path:    d.property.path,
+			Snippet: snippet(d.property.description, terms),
+		})
+	}
+	return found, nil
+}
+
+// words splits text into lower case words, camel case names included, e.g. zoneRedundant and zone-redundant are both zone redundant.
+func words(text string) []string {
+	var result []string
+	var word []rune
+	runes := []rune(text)
+	flush := func() {
+		if len(word) > 0 {
+			result = append(result, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && i > 0 && len(word) > 0 {
+			previous := runes[i-1]
+			next := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || (unicode.IsUpper(previous) && next) {
+				flush()
+			}
+		}
+		word = append(word, r)
+	}
+	flush()
+	return result
+}
+
+// matchCount counts the words matching term, plurals and words differing by their last letter match too, e.g. redundant and redundancy.
+func matchCount(words []string, term string) int {
+	count := 0
+	for _, w := range words {
+		if w == term || sameStem(w, term) {
+			count++
+		}
+	}
+	return count
+}
+
+// sameStem tells whether a and b only differ by endings of the same group of stemEndings.
+func sameStem(a, b string) bool {
+	for _, endings := range stemEndings {
+		for _, ending := range endings {
+			stem, ok := strings.CutSuffix(a, ending)
+			if !ok || len(stem) < minStemLength {
+				continue
+			}
+			if slices.ContainsFunc(endings, func(other string) bool { return b == stem+other }) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stem returns word without its longest ending of stemEndings.
+func stem(word string) string {
+	result := word
+	for _, endings := range stemEndings {
+		for _, ending := range endings {
+			if s, ok := strings.CutSuffix(word, ending); ok && len(s) >= minStemLength && len(s) < len(result) {
+				result = s
+			}
+		}
+	}
+	return result
+}
+
+func logFrequency(count int) float64 {
+	if count == 0 {
+		return 0
+	}
+	return 1 + math.Log(float64(count))
+}
+
+// snippet returns the part of a description around the first word matching one of terms.
+func snippet(description string, terms []string) string {
+	description = strings.Join(strings.Fields(description), " ")
+	lower := strings.ToLower(description)
+	if len(lower) != len(description) {
+		// Lower casing changed the length of some characters, the indexes wouldn't match.
+		lower = description
+	}
+	start := -1
+	for _, term := range terms {
+		// The stem of the term finds its other endings too.
+		if i := strings.Index(lower, stem(term)); i >= 0 && (start < 0 || i < start) {
+			start = i
+		}
+	}
+	if start < 0 || len(description) <= 2*snippetRadius {
+		return truncate(description, 2*snippetRadius)
+	}
+	from := runeStart(description, max(start-snippetRadius, 0))
+	to := runeStart(description, min(start+snippetRadius, len(description)))
+	// Cut at spaces so words are whole.
+	if from > 0 {
+		if i := strings.Index(description[from:], " "); i >= 0 && from+i < start {
+			from += i + 1
+		}
+	}
+	if to < len(description) {
+		if i := strings.LastIndex(description[:to], " "); i > start {
+			to = i
+		}
+	}
+	result := description[from:to]
+	if from > 0 {
+		result = "..." + result
+	}
+	if to < len(description) {
+		result += "..."
+	}
+	return result
+}
+
+func truncate(text string, length int) string {
+	if len(text) <= length {
+		return text
+	}
+	length = runeStart(text, length)
+	if i := strings.LastIndex(text[:length], " "); i > 0 {
+		length = i
+	}
+	return text[:length] + "..."
+}
+
+// runeStart moves the index i of s back to the start of the rune it's in.
+func runeStart(s string, i int) int {
+	for i > 0 && i < len(s) && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return i
+}
@@ -0,0 +1,82 @@
+package azapi
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchProperties_RanksNameMatchesFirst(t *testing.T) {
+	results, err := SearchProperties(context.Background(), "Microsoft.ContainerService/managedClusters", "workload identity")
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	assert.Regexp(t, `^Microsoft.ContainerService/managedClusters@`, results[0].Type)
+	assert.Equal(t, "body.properties.securityProfile.workloadIdentity", results[0].Path)
+	assert.Contains(t, strings.ToLower(results[0].Snippet), "workload identity")
+}
+
+func TestSearchProperties_ResourceTypeExcludesChildTypes(t *testing.T) {
+	results, err := SearchProperties(context.Background(), "microsoft.storage/storageaccounts", "redundant")
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	for _, r := range results {
+		assert.Regexp(t, `^Microsoft.Storage/storageAccounts@`, r.Type)
+	}
+	// Redundant matches redundancy.
+	assert.Contains(t, results[0].Path, "Redundancy")
+
+	_, err = SearchProperties(context.Background(), "Microsoft.Storage", "redundant")
+	assert.ErrorContains(t, err, "end it with /")
+}
+
+func TestSearchProperties_Prefix(t *testing.T) {
+	results, err := SearchProperties(context.Background(), "Microsoft.Network/virtualNetworks/", "private endpoint network policies")
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	assert.Regexp(t, `^Microsoft.Network/virtualNetworks/subnets@`, results[0].Type)
+	assert.Equal(t, "body.properties.privateEndpointNetworkPolicies", results[0].Path)
+}
+
+func TestSearchProperties_NoWords(t *testing.T) {
+	_, err := SearchProperties(context.Background(), "Microsoft.Storage/storageAccounts", " - ")
+	assert.ErrorContains(t, err, "search text has no words")
+}
+
+func TestWords(t *testing.T) {
+	assert.Equal(t, []string{"zone", "redundant"}, words("zoneRedundant"))
+	assert.Equal(t, []string{"zone", "redundant", "storage"}, words("Zone-redundant storage"))
+	assert.Equal(t, []string{"public", "ip", "address"}, words("publicIPAddress"))
+}
+
+func TestSameStem(t *testing.T) {
+	assert.True(t, sameStem("redundant", "redundancy"))
+	assert.True(t, sameStem("policy", "policies"))
+	assert.True(t, sameStem("key", "keys"))
+	assert.True(t, sameStem("independence", "independent"))
+	assert.False(t, sameStem("custom", "customer"))
+	assert.False(t, sameStem("public", "publish"))
+	assert.False(t, sameStem("enable", "enabled"))
+}
+
+func TestSnippet(t *testing.T) {
+	description := strings.Repeat("lorem ipsum ", 20) + "the zone redundant setting " + strings.Repeat("dolor sit ", 20)
+	s := snippet(description, []string{"redundant"})
+	assert.True(t, strings.HasPrefix(s, "..."))
+	assert.True(t, strings.HasSuffix(s, "..."))
+	assert.Contains(t, s, "zone redundant setting")
+	assert.Equal(t, "short", snippet("short", []string{"missing"}))
+}
+
+func TestSnippet_CutsAtRuneBoundaries(t *testing.T) {
+	for _, padding := range []string{"", "x", "xx"} {
+		description := padding + strings.Repeat("é", 100) + "redundant" + strings.Repeat("ü", 100)
+		s := snippet(description, []string{"redundant"})
+		assert.True(t, utf8.ValidString(s), s)
+		assert.Contains(t, s, "redundant")
+		assert.True(t, utf8.ValidString(truncate(description, 2*snippetRadius)))
+	}
+}
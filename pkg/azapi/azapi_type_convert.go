@@ -0,0 +1,193 @@
+package azapi
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/ms-henglu/go-azure-types/types"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// rootAttributes are the body properties azapi_resource has as top-level attributes.
+var rootAttributes = []string{"identity", "location", "name", "tags"}
+
+// typeConverter converts AzAPI types to cty types the way newres does, read-only properties are left out
+// and discriminated objects are dynamic, with their variants in a comment. Swagger types can be nested in themselves,
+// an object nested in itself is replaced with a $ref to the path of its outer occurrence instead of being converted forever.
+type typeConverter struct {
+	// ancestors are the objects being converted, with their path.
+	ancestors map[types.TypeBase]string
+	// response converts the GET response instead, which has the read-only properties but not the write-only ones.
+	response bool
+	// optional makes every property optional, for blocks which only set the properties they change.
+	optional bool
+}
+
+func newTypeConverter() *typeConverter {
+	return &typeConverter{ancestors: make(map[types.TypeBase]string)}
+}
+
+// bodyAttributes converts the body of a block to the attributes it sets: body and the root attributes.
+func (c *typeConverter) bodyAttributes(b *block) map[string]cty.Type {
+	if _, ok := b.body.(*types.ObjectType); !ok {
+		return map[string]cty.Type{"body": c.convert(b.body, "body")}
+	}
+	c.ancestors[b.body] = "body"
+	defer delete(c.ancestors, b.body)
+	attributes := make(map[string]cty.Type)
+	root, body := b.bodyProperties()
+	for name, p := range root {
+		attributes[name] = c.convert(p.Type.Type, name)
+	}
+	attributes["body"] = c.object(body, "body")
+	return attributes
+}
+
+func (c *typeConverter) convert(t types.TypeBase, path string) cty.Type {
+	switch t := t.(type) {
+	case *types.StringType, *types.StringLiteralType:
+		return cty.String
+	case *types.IntegerType:
+		return cty.Number
+	case *types.BooleanType:
+		return cty.Bool
+	case *types.ArrayType:
+		if t.ItemType == nil {
+			return cty.List(cty.DynamicPseudoType)
+		}
+		return cty.List(c.convert(t.ItemType.Type, path))
+	case *types.ObjectType:
+		if ref, ok := c.ancestors[t]; ok {
+			return refType(ref)
+		}
+		c.ancestors[t] = path
+		defer delete(c.ancestors, t)
+		if len(t.Properties) == 0 && t.AdditionalProperties != nil {
+			return cty.Map(c.convert(t.AdditionalProperties.Type, path))
+		}
+		return c.object(t.Properties, path)
+	case *types.DiscriminatedObjectType:
+		return discriminatedType(t, path)
+	case *types.UnionType:
+		if len(t.Elements) > 0 {
+			if _, ok := t.Elements[0].Type.(*types.StringLiteralType); ok {
+				return cty.String
+			}
+		}
+	}
+	return cty.DynamicPseudoType
+}
+
+func (c *typeConverter) object(properties map[string]types.ObjectProperty, path string) cty.Type {
+	attributes := make(map[string]cty.Type)
+	var optional []string
+	for name, p := range properties {
+		if c.excluded(p) || p.Type == nil {
+			continue
+		}
+		if c.optional || !p.IsRequired() {
+			optional = append(optional, name)
+		}
+		attributes[name] = c.convert(p.Type.Type, joinPath(path, name))
+	}
+	if len(optional) > 0 {
+		return cty.ObjectWithOptionalAttrs(attributes, optional)
+	}
+	return cty.Object(attributes)
+}
+
+func (c *typeConverter) excluded(p types.ObjectProperty) bool {
+	if c.response {
+		return slices.Contains(p.Flags, types.WriteOnly)
+	}
+	return readOnly(p)
+}
+
+// readOnly properties can't be set, so they're not part of the body type.
+func readOnly(p types.ObjectProperty) bool {
+	for _, flag := range p.Flags {
+		if flag == types.ReadOnly || flag == types.Identifier {
+			return true
+		}
+	}
+	return false
+}
+
+// refType stands for an object nested in itself, it's dynamic and renders as `DynamicPseudoType /* $ref: path */`,
+// path is where the object's properties can be queried.
+func refType(path string) cty.Type {
+	return cty.CapsuleWithOps("$ref "+path, reflect.TypeOf(path), &cty.CapsuleOps{
+		TypeGoString: func(reflect.Type) string {
+			return fmt.Sprintf("cty.DynamicPseudoType /* $ref: %s */", path)
+		},
+	})
+}
+
+// findProperty follows path, e.g. properties.osProfile, from t through objects and the elements of arrays and maps.
+// A segment like `properties[type=AzureBlobStorage]` selects a variant of a discriminated object.
+// prefix is the path of t, it's only used in errors.
+func findProperty(t types.TypeBase, prefix, path string) (types.ObjectProperty, error) {
+	property := types.ObjectProperty{Type: &types.TypeReference{Type: t}}
+	segments := splitPath(path)
+	parent := prefix
+	for i, segment := range segments {
+		current := joinPath(prefix, strings.Join(segments[:i+1], "."))
+		name, discriminator, value, err := parseSegment(segment)
+		if err != nil {
+			return types.ObjectProperty{}, err
+		}
+		p, ok := objectProperties(property.Type.Type)[name]
+		if !ok || p.Type == nil {
+			err := fmt.Errorf("property '%s' not found at path '%s'", name, current)
+			if d, ok := propertiesOwner(property.Type.Type).(*types.DiscriminatedObjectType); ok {
+				err = fmt.Errorf("%w, the object is discriminated by %s, select a variant with %s[%s=<value>]", err, d.Discriminator, parent, d.Discriminator)
+			}
+			return types.ObjectProperty{}, err
+		}
+		if discriminator != "" {
+			d, ok := propertiesOwner(p.Type.Type).(*types.DiscriminatedObjectType)
+			if !ok {
+				return types.ObjectProperty{}, fmt.Errorf("property '%s' at path '%s' is not a discriminated object", name, current)
+			}
+			variant, err := variantType(d, discriminator, value)
+			if err != nil {
+				return types.ObjectProperty{}, fmt.Errorf("invalid variant at path '%s': %w", current, err)
+			}
+			p.Type = &types.TypeReference{Type: variant}
+		}
+		property = p
+		parent = current
+	}
+	return property, nil
+}
+
+// objectProperties returns the properties of an object, or of the elements of an array or map of objects.
+func objectProperties(t types.TypeBase) map[string]types.ObjectProperty {
+	switch t := propertiesOwner(t).(type) {
+	case *types.ObjectType:
+		return t.Properties
+	case *types.DiscriminatedObjectType:
+		return t.BaseProperties
+	}
+	return nil
+}
+
+// propertiesOwner returns the object holding the properties of t, which is t itself or the element type of an array or map.
+func propertiesOwner(t types.TypeBase) types.TypeBase {
+	switch t := t.(type) {
+	case *types.ObjectType:
+		if len(t.Properties) == 0 && t.AdditionalProperties != nil {
+			return propertiesOwner(t.AdditionalProperties.Type)
+		}
+		return t
+	case *types.DiscriminatedObjectType:
+		return t
+	case *types.ArrayType:
+		if t.ItemType != nil {
+			return propertiesOwner(t.ItemType.Type)
+		}
+	}
+	return nil
+}
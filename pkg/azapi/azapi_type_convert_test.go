@@ -0,0 +1,59 @@
+package azapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ms-henglu/go-azure-types/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recursiveFilter is a filter of and/or filters, like the Cost Management query filter.
+func recursiveFilter() *types.ObjectType {
+	description := "The name."
+	filter := &types.ObjectType{Properties: map[string]types.ObjectProperty{
+		"name": {Type: &types.TypeReference{Type: &types.StringType{}}, Description: &description},
+	}}
+	filter.Properties["and"] = types.ObjectProperty{Type: &types.TypeReference{Type: &types.ArrayType{ItemType: &types.TypeReference{Type: filter}}}}
+	filter.Properties["not"] = types.ObjectProperty{Type: &types.TypeReference{Type: filter}}
+	return filter
+}
+
+func TestTypeConverter_RecursiveTypeIsRef(t *testing.T) {
+	converted := newTypeConverter().convert(recursiveFilter(), "body.filter")
+	assert.Equal(t, `ObjectWithOptionalAttrs(map[string]Type{"and":List(DynamicPseudoType /* $ref: body.filter */), "name":String, "not":DynamicPseudoType /* $ref: body.filter */}, []string{"and", "name", "not"})`, compactGoType(converted.GoString()))
+}
+
+func TestConvertObjectTypeToMap_RecursiveTypeIsRef(t *testing.T) {
+	descriptions, err := convertObjectTypeToMap(recursiveFilter(), "body.filter", make(map[types.TypeBase]string))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"$ref": "body.filter"}, descriptions["not"])
+	assert.Equal(t, "The name.", descriptions["name"])
+}
+
+func TestOutlineProperty_RecursiveTypeIsRef(t *testing.T) {
+	property := types.ObjectProperty{Type: &types.TypeReference{Type: recursiveFilter()}}
+	outline := outlineProperty("filter", "body.filter", property, 5, make(map[types.TypeBase]string))
+	text, _ := RenderOutline(&outline, false)
+	assert.Equal(t, "filter: object\n  and: array<object> {$ref: body.filter}\n  name: string\n  not: object {$ref: body.filter}", text)
+}
+
+func TestGetResourceType_RecursiveResourceType(t *testing.T) {
+	resourceType, err := GetResourceType(context.Background(), "Microsoft.CostManagement/views", "2024-08-01", "")
+	require.NoError(t, err)
+	rendered, _ := RenderType(resourceType, "", 0)
+	assert.Contains(t, rendered, `"and":List(DynamicPseudoType /* $ref: body.properties.query.dataSet.filter */)`)
+}
+
+func TestGetResourceType_PathThroughRecursiveType(t *testing.T) {
+	resourceType, err := GetResourceType(context.Background(), "Microsoft.CostManagement/views", "2024-08-01", "body.properties.query.dataSet.filter.and.or.dimensions")
+	require.NoError(t, err)
+	assert.Equal(t, `Object(map[string]Type{"name":String, "operator":String, "values":List(String)})`, compactGoType(resourceType.GoString()))
+}
+
+func TestGetResourceSchemaDescription_PathThroughRecursiveType(t *testing.T) {
+	description, err := GetResourceSchemaDescription(context.Background(), "Microsoft.CostManagement/views", "2024-08-01", "body.properties.query.dataSet.filter.or.tags.name")
+	require.NoError(t, err)
+	assert.Contains(t, description, "The name of the column to use in comparison.")
+}
@@ -0,0 +1,131 @@
+package azapi
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// collapsedObject replaces objects nested deeper than the maximum depth.
+const collapsedObject = "Object({...})"
+
+// RenderType renders t like compactGoType(t.GoString()), but objects nested more than maxDepth levels deep are collapsed to `Object({...})`.
+// Collection types don't count as a level. The paths of the collapsed objects, prefixed with path, are returned so they can be queried next.
+// A maxDepth of 0 renders the whole type.
+func RenderType(t cty.Type, path string, maxDepth int) (string, []string) {
+	if maxDepth <= 0 {
+		return compactGoType(t.GoString()), nil
+	}
+	r := &typeRenderer{maxDepth: maxDepth}
+	r.render(t, path, 0)
+	return compactGoType(r.sb.String()), r.collapsed
+}
+
+type typeRenderer struct {
+	sb        strings.Builder
+	maxDepth  int
+	collapsed []string
+}
+
+func (r *typeRenderer) render(t cty.Type, path string, depth int) {
+	switch {
+	case t.IsObjectType():
+		attrs := t.AttributeTypes()
+		if len(attrs) == 0 {
+			r.sb.WriteString("cty.EmptyObject")
+			return
+		}
+		if depth >= r.maxDepth {
+			r.sb.WriteString(collapsedObject)
+			r.collapsed = append(r.collapsed, path)
+			return
+		}
+		names := make([]string, 0, len(attrs))
+		var optional []string
+		for name := range attrs {
+			names = append(names, name)
+			if t.AttributeOptional(name) {
+				optional = append(optional, name)
+			}
+		}
+		slices.Sort(names)
+		if len(optional) > 0 {
+			r.sb.WriteString("cty.ObjectWithOptionalAttrs(map[string]cty.Type{")
+		} else {
+			r.sb.WriteString("cty.Object(map[string]cty.Type{")
+		}
+		for i, name := range names {
+			if i > 0 {
+				r.sb.WriteString(", ")
+			}
+			fmt.Fprintf(&r.sb, "%q:", name)
+			r.render(attrs[name], joinPath(path, name), depth+1)
+		}
+		r.sb.WriteString("}")
+		if len(optional) > 0 {
+			slices.Sort(optional)
+			fmt.Fprintf(&r.sb, ", %#v", optional)
+		}
+		r.sb.WriteString(")")
+	case t.IsListType():
+		r.renderCollection("cty.List(", t.ElementType(), path, depth)
+	case t.IsSetType():
+		r.renderCollection("cty.Set(", t.ElementType(), path, depth)
+	case t.IsMapType():
+		r.renderCollection("cty.Map(", t.ElementType(), path, depth)
+	case t.IsTupleType():
+		r.sb.WriteString("cty.Tuple([]cty.Type{")
+		for i, et := range t.TupleElementTypes() {
+			if i > 0 {
+				r.sb.WriteString(", ")
+			}
+			r.render(et, path, depth)
+		}
+		r.sb.WriteString("})")
+	default:
+		r.sb.WriteString(t.GoString())
+	}
+}
+
+func (r *typeRenderer) renderCollection(prefix string, element cty.Type, path string, depth int) {
+	r.sb.WriteString(prefix)
+	r.render(element, path, depth)
+	r.sb.WriteString(")")
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// CollapseDescription copies a description returned by GetResourceSchemaDescription, replacing objects nested more than maxDepth levels deep with `{...}`.
+// The paths of the collapsed objects, prefixed with path, are returned so they can be queried next. A maxDepth of 0 returns description as is.
+func CollapseDescription(description any, path string, maxDepth int) (any, []string) {
+	if maxDepth <= 0 {
+		return description, nil
+	}
+	var collapsed []string
+	var collapse func(v any, path string, depth int) any
+	collapse = func(v any, path string, depth int) any {
+		m, ok := v.(map[string]any)
+		if !ok || len(m) == 0 {
+			return v
+		}
+		if depth >= maxDepth {
+			collapsed = append(collapsed, path)
+			return "{...}"
+		}
+		result := make(map[string]any, len(m))
+		for name, child := range m {
+			result[name] = collapse(child, joinPath(path, name), depth+1)
+		}
+		return result
+	}
+	result := collapse(description, path, 0)
+	slices.Sort(collapsed)
+	return result, collapsed
+}
@@ -0,0 +1,66 @@
+package azapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestRenderType_UnlimitedMatchesGoString(t *testing.T) {
+	resourceType, err := GetResourceType(context.Background(), "Microsoft.Web/sites", "2024-04-01", "")
+	require.NoError(t, err)
+	rendered, collapsed := RenderType(resourceType, "", 1000)
+	assert.Equal(t, compactGoType(resourceType.GoString()), rendered)
+	assert.Empty(t, collapsed)
+}
+
+func TestRenderType_CollapsesDeepObjects(t *testing.T) {
+	typ := cty.Object(map[string]cty.Type{
+		"name": cty.String,
+		"properties": cty.ObjectWithOptionalAttrs(map[string]cty.Type{
+			"tags": cty.Map(cty.String),
+			"rules": cty.List(cty.Object(map[string]cty.Type{
+				"port": cty.Number,
+			})),
+		}, []string{"tags"}),
+	})
+	rendered, collapsed := RenderType(typ, "body", 1)
+	assert.Equal(t, `Object(map[string]Type{"name":String, "properties":Object({...})})`, rendered)
+	assert.Equal(t, []string{"body.properties"}, collapsed)
+
+	rendered, collapsed = RenderType(typ, "body", 2)
+	assert.Equal(t, `Object(map[string]Type{"name":String, "properties":ObjectWithOptionalAttrs(map[string]Type{"rules":List(Object({...})), "tags":Map(String)}, []string{"tags"})})`, rendered)
+	assert.Equal(t, []string{"body.properties.rules"}, collapsed)
+
+	rendered, _ = RenderType(typ, "", 3)
+	assert.Equal(t, compactGoType(typ.GoString()), rendered)
+}
+
+func TestCollapseDescription(t *testing.T) {
+	description := map[string]any{
+		"name": "The name.",
+		"properties": map[string]any{
+			"enabled": "Whether it's enabled.",
+			"rules": map[string]any{
+				"port": "The port.",
+			},
+		},
+	}
+	collapsed, paths := CollapseDescription(description, "body", 2)
+	assert.Equal(t, map[string]any{
+		"name": "The name.",
+		"properties": map[string]any{
+			"enabled": "Whether it's enabled.",
+			"rules":   "{...}",
+		},
+	}, collapsed)
+	assert.Equal(t, []string{"body.properties.rules"}, paths)
+	assert.Equal(t, "The port.", description["properties"].(map[string]any)["rules"].(map[string]any)["port"])
+
+	same, paths := CollapseDescription(description, "", 0)
+	assert.Equal(t, description, same)
+	assert.Empty(t, paths)
+}
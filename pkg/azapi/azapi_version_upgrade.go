@@ -0,0 +1,394 @@
+package azapi
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/ms-henglu/go-azure-types/types"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ApiVersionUpgrade tells what breaks when the api-version of an azapi_resource block is changed, with the block rewritten for the new api-version.
+type ApiVersionUpgrade struct {
+	// Type is the new value of the `type` attribute.
+	Type string `json:"type"`
+	// Invalid are the body properties the new api-version doesn't accept: unknown, read-only, or with a value which is no longer allowed.
+	Invalid []UpgradeFinding `json:"invalid,omitempty"`
+	// RemovedValues are the possible values of the body properties the new api-version dropped.
+	RemovedValues []UpgradeFinding `json:"removed_values,omitempty"`
+	// Required are the properties the new api-version requires which the body doesn't set.
+	Required []UpgradeFinding `json:"required,omitempty"`
+	// Block is the block rewritten for the new api-version, invalid properties are removed with their comments and required ones added as TODOs.
+	Block string `json:"-"`
+}
+
+// UpgradeFinding is a body property affected by an api-version upgrade.
+type UpgradeFinding struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// CheckApiVersionUpgrade checks the body of the first azapi_resource block in src against apiVersion.
+// Only the literal parts of the body can be checked, expressions such as variables are kept as they are.
+func CheckApiVersionUpgrade(ctx context.Context, src, apiVersion string) (*ApiVersionUpgrade, error) {
+	file, diags := hclsyntax.ParseConfig([]byte(src), "main.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse HCL: %s", diags.Error())
+	}
+	var block *hclsyntax.Block
+	for _, b := range file.Body.(*hclsyntax.Body).Blocks {
+		if b.Type == "resource" && len(b.Labels) > 0 && b.Labels[0] == BlockResource {
+			block = b
+			break
+		}
+	}
+	if block == nil {
+		return nil, fmt.Errorf("no %s block found", BlockResource)
+	}
+	typeAttribute, ok := block.Body.Attributes["type"]
+	if !ok {
+		return nil, fmt.Errorf("the %s block has no type attribute", BlockResource)
+	}
+	typeValue, diags := typeAttribute.Expr.Value(nil)
+	if diags.HasErrors() || typeValue.Type() != cty.String || !typeValue.IsKnown() || typeValue.IsNull() {
+		return nil, fmt.Errorf("the type attribute must be a literal string like Microsoft.Network/virtualNetworks@2024-05-01")
+	}
+	resourceType, currentVersion, ok := strings.Cut(typeValue.AsString(), "@")
+	if !ok {
+		return nil, fmt.Errorf("invalid type %s, it must be like Microsoft.Network/virtualNetworks@2024-05-01", typeValue.AsString())
+	}
+
+	targetBody, err := getBodyType(ctx, resourceType, apiVersion)
+	if err != nil {
+		return nil, err
+	}
+	// The current api-version is only needed to tell what changed, the body is still checked when it's unknown.
+	var currentBody types.TypeBase
+	if body, err := getBodyType(ctx, resourceType, currentVersion); err == nil {
+		currentBody = body
+	}
+	c := &upgradeChecker{
+		src:        []byte(src),
+		apiVersion: apiVersion,
+		result:     &ApiVersionUpgrade{Type: resourceType + "@" + apiVersion},
+	}
+	// The body is rewritten from its expressions, the comments between them are put back from the tokens.
+	tokens, _ := hclsyntax.LexConfig(c.src, "main.tf", hcl.InitialPos)
+	for _, t := range tokens {
+		if t.Type == hclsyntax.TokenComment {
+			c.comments = append(c.comments, t)
+		}
+	}
+
+	out, diags := hclwrite.ParseConfig([]byte(src), "main.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse HCL: %s", diags.Error())
+	}
+	var outBlock *hclwrite.Block
+	for _, b := range out.Body().Blocks() {
+		if b.Type() == "resource" && len(b.Labels()) > 0 && b.Labels()[0] == BlockResource {
+			outBlock = b
+			break
+		}
+	}
+	outBlock.Body().SetAttributeValue("type", cty.StringVal(c.result.Type))
+	if bodyAttribute, ok := block.Body.Attributes["body"]; ok {
+		expr := bodyAttribute.Expr
+		// Bodies written for azapi v1 are wrapped in jsonencode.
+		wrap := "%s"
+		if call, ok := expr.(*hclsyntax.FunctionCallExpr); ok && call.Name == "jsonencode" && len(call.Args) == 1 {
+			expr = call.Args[0]
+			wrap = "jsonencode(%s)"
+		}
+		body := c.check(expr, currentBody, targetBody, "body")
+		tokens, err := expressionTokens(fmt.Sprintf(wrap, body))
+		if err != nil {
+			return nil, err
+		}
+		outBlock.Body().SetAttributeRaw("body", tokens)
+	}
+	c.result.Block = strings.TrimSpace(string(hclwrite.Format(outBlock.BuildTokens(nil).Bytes())))
+	return c.result, nil
+}
+
+type upgradeChecker struct {
+	src        []byte
+	comments   []hclsyntax.Token
+	apiVersion string
+	result     *ApiVersionUpgrade
+}
+
+// check checks expr against the target type and returns it rewritten for the target api-version.
+// current is the type in the current api-version, nil when it's unknown.
+func (c *upgradeChecker) check(expr hclsyntax.Expression, current, target types.TypeBase, path string) string {
+	value, comment := c.checkValue(expr, current, target, path)
+	return value + comment
+}
+
+// checkValue is check returning the TODO comment to put after the value apart, an item separator goes in between.
+func (c *upgradeChecker) checkValue(expr hclsyntax.Expression, current, target types.TypeBase, path string) (value, comment string) {
+	if target == nil {
+		return c.source(expr), ""
+	}
+	if values, _ := enumValues(current); len(values) > 0 {
+		targetValues, _ := enumValues(target)
+		var removed []string
+		for _, v := range values {
+			if !slices.ContainsFunc(targetValues, func(t string) bool { return strings.EqualFold(t, v) }) {
+				removed = append(removed, v)
+			}
+		}
+		if len(removed) > 0 {
+			c.result.RemovedValues = append(c.result.RemovedValues, UpgradeFinding{Path: path, Message: fmt.Sprintf("values removed in api-version %s: %s", c.apiVersion, strings.Join(removed, ", "))})
+		}
+	}
+	switch expr := expr.(type) {
+	case *hclsyntax.ObjectConsExpr:
+		if owner, ok := target.(*types.ObjectType); ok && len(owner.Properties) == 0 && owner.AdditionalProperties != nil {
+			return c.checkMap(expr, current, owner.AdditionalProperties.Type, path), ""
+		}
+		return c.checkObject(expr, current, target, path), ""
+	case *hclsyntax.TupleConsExpr:
+		array, ok := target.(*types.ArrayType)
+		if !ok || array.ItemType == nil {
+			return c.source(expr), ""
+		}
+		var currentItem types.TypeBase
+		if a, ok := current.(*types.ArrayType); ok && a.ItemType != nil {
+			currentItem = a.ItemType.Type
+		}
+		// One item per line, a TODO comment can follow each.
+		ranges := make([]hcl.Range, 0, len(expr.Exprs))
+		for _, item := range expr.Exprs {
+			ranges = append(ranges, item.Range())
+		}
+		leading, trailing := c.itemComments(expr, ranges)
+		var sb strings.Builder
+		sb.WriteString("[\n")
+		for i, item := range expr.Exprs {
+			value, comment := c.checkValue(item, currentItem, array.ItemType.Type, path)
+			sb.WriteString(leading[i] + value)
+			if i < len(expr.Exprs)-1 {
+				sb.WriteString(",")
+			}
+			sb.WriteString(comment + trailing[i] + "\n")
+		}
+		sb.WriteString(leading[len(expr.Exprs)] + "]")
+		return sb.String(), ""
+	}
+	if value, ok := literalString(expr); ok {
+		if values, closed := enumValues(target); closed && !slices.ContainsFunc(values, func(v string) bool { return strings.EqualFold(v, value) }) {
+			c.result.Invalid = append(c.result.Invalid, UpgradeFinding{Path: path, Message: fmt.Sprintf("value %q is not allowed in api-version %s, possible values: %s", value, c.apiVersion, strings.Join(values, ", "))})
+			return c.source(expr), " # TODO: not allowed in api-version " + c.apiVersion
+		}
+	}
+	return c.source(expr), ""
+}
+
+func (c *upgradeChecker) checkObject(expr *hclsyntax.ObjectConsExpr, current, target types.TypeBase, path string) string {
+	targetProperties := c.variantProperties(expr, target, path)
+	var currentProperties map[string]types.ObjectProperty
+	if current != nil {
+		currentProperties = c.variantProperties(expr, current, "")
+	}
+	if targetProperties == nil {
+		return c.source(expr)
+	}
+	leading, trailing := c.objectComments(expr)
+	var sb strings.Builder
+	sb.WriteString("{\n")
+	set := make(map[string]bool)
+	for i, item := range expr.Items {
+		name, ok := keyName(item.KeyExpr)
+		if !ok {
+			fmt.Fprintf(&sb, "%s%s = %s%s\n", leading[i], c.source(item.KeyExpr), c.source(item.ValueExpr), trailing[i])
+			continue
+		}
+		set[name] = true
+		itemPath := joinPath(path, name)
+		property, ok := targetProperties[name]
+		switch {
+		case !ok || property.Type == nil:
+			c.result.Invalid = append(c.result.Invalid, UpgradeFinding{Path: itemPath, Message: fmt.Sprintf("not a property in api-version %s, it's removed", c.apiVersion)})
+			continue
+		case readOnly(property):
+			c.result.Invalid = append(c.result.Invalid, UpgradeFinding{Path: itemPath, Message: fmt.Sprintf("read-only in api-version %s, it's removed", c.apiVersion)})
+			continue
+		}
+		var currentType types.TypeBase
+		if p, ok := currentProperties[name]; ok && p.Type != nil {
+			currentType = p.Type.Type
+		}
+		fmt.Fprintf(&sb, "%s%s = %s%s\n", leading[i], c.source(item.KeyExpr), c.check(item.ValueExpr, currentType, property.Type.Type, itemPath), trailing[i])
+	}
+
+	names := make([]string, 0, len(targetProperties))
+	for name := range targetProperties {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		property := targetProperties[name]
+		// The root attributes of azapi_resource, such as name and location, are set outside the body.
+		if set[name] || !property.IsRequired() || readOnly(property) || (path == "body" && slices.Contains(rootAttributes, name)) {
+			continue
+		}
+		message := "required, but not set"
+		if p, ok := currentProperties[name]; currentProperties != nil && (!ok || !p.IsRequired()) {
+			message = fmt.Sprintf("newly required in api-version %s", c.apiVersion)
+		}
+		c.result.Required = append(c.result.Required, UpgradeFinding{Path: joinPath(path, name), Message: message})
+		fmt.Fprintf(&sb, "%s = null # TODO: %s\n", name, message)
+	}
+	sb.WriteString(leading[len(expr.Items)] + "}")
+	return sb.String()
+}
+
+// checkMap checks the values of a map, its keys are free.
+func (c *upgradeChecker) checkMap(expr *hclsyntax.ObjectConsExpr, current, target types.TypeBase, path string) string {
+	var currentValue types.TypeBase
+	if o, ok := current.(*types.ObjectType); ok && o.AdditionalProperties != nil {
+		currentValue = o.AdditionalProperties.Type
+	}
+	leading, trailing := c.objectComments(expr)
+	var sb strings.Builder
+	sb.WriteString("{\n")
+	for i, item := range expr.Items {
+		itemPath := path
+		if name, ok := keyName(item.KeyExpr); ok {
+			itemPath = joinPath(path, name)
+		}
+		fmt.Fprintf(&sb, "%s%s = %s%s\n", leading[i], c.source(item.KeyExpr), c.check(item.ValueExpr, currentValue, target, itemPath), trailing[i])
+	}
+	sb.WriteString(leading[len(expr.Items)] + "}")
+	return sb.String()
+}
+
+// variantProperties returns the properties of an object, for a discriminated object the properties of the variant the object selects.
+// An invalid variant is reported when path is set.
+func (c *upgradeChecker) variantProperties(expr *hclsyntax.ObjectConsExpr, t types.TypeBase, path string) map[string]types.ObjectProperty {
+	switch t := t.(type) {
+	case *types.ObjectType:
+		return t.Properties
+	case *types.DiscriminatedObjectType:
+		for _, item := range expr.Items {
+			if name, ok := keyName(item.KeyExpr); ok && name == t.Discriminator {
+				value, ok := literalString(item.ValueExpr)
+				if !ok {
+					break
+				}
+				variant, err := variantType(t, t.Discriminator, value)
+				if err != nil {
+					if path != "" {
+						c.result.Invalid = append(c.result.Invalid, UpgradeFinding{Path: joinPath(path, name), Message: fmt.Sprintf("invalid in api-version %s: %s", c.apiVersion, err)})
+					}
+					break
+				}
+				return variant.Properties
+			}
+		}
+		// Without a known variant only the base properties are known, the discriminator is one of them.
+		properties := make(map[string]types.ObjectProperty, len(t.BaseProperties)+1)
+		for name, p := range t.BaseProperties {
+			properties[name] = p
+		}
+		properties[t.Discriminator] = types.ObjectProperty{Type: &types.TypeReference{Type: &types.StringType{}}, Flags: []types.ObjectPropertyFlag{types.Required}}
+		return properties
+	}
+	return nil
+}
+
+func (c *upgradeChecker) objectComments(expr *hclsyntax.ObjectConsExpr) (leading, trailing []string) {
+	ranges := make([]hcl.Range, 0, len(expr.Items))
+	for _, item := range expr.Items {
+		ranges = append(ranges, hcl.RangeBetween(item.KeyExpr.Range(), item.ValueExpr.Range()))
+	}
+	return c.itemComments(expr, ranges)
+}
+
+// itemComments returns the comments between the items of a collection expr, the comments inside the items are part of their source.
+// leading[i] are the lines of comments before item i, the last one those before the closing bracket,
+// trailing[i] the comments after item i on its last line.
+func (c *upgradeChecker) itemComments(expr hclsyntax.Expression, items []hcl.Range) (leading, trailing []string) {
+	leading = make([]string, len(items)+1)
+	trailing = make([]string, len(items))
+	from := expr.Range().Start.Byte
+	for i := 0; i <= len(items); i++ {
+		to := expr.Range().End.Byte
+		if i < len(items) {
+			to = items[i].Start.Byte
+		}
+		for _, t := range c.comments {
+			if t.Range.Start.Byte < from || t.Range.End.Byte > to {
+				continue
+			}
+			comment := strings.TrimRight(string(t.Bytes), "\r\n")
+			if i > 0 && t.Range.Start.Line == items[i-1].End.Line {
+				trailing[i-1] += " " + comment
+			} else {
+				leading[i] += comment + "\n"
+			}
+		}
+		if i < len(items) {
+			from = items[i].End.Byte
+		}
+	}
+	return leading, trailing
+}
+
+func (c *upgradeChecker) source(expr hclsyntax.Expression) string {
+	return string(expr.Range().SliceBytes(c.src))
+}
+
+// enumValues returns the possible values of a string enum, closed is false when other strings are allowed too.
+func enumValues(t types.TypeBase) (values []string, closed bool) {
+	switch t := t.(type) {
+	case *types.StringLiteralType:
+		return []string{t.Value}, true
+	case *types.UnionType:
+		closed = true
+		for _, element := range t.Elements {
+			switch e := element.Type.(type) {
+			case *types.StringLiteralType:
+				values = append(values, e.Value)
+			default:
+				closed = false
+			}
+		}
+		return values, closed && len(values) > 0
+	}
+	return nil, false
+}
+
+func keyName(expr hclsyntax.Expression) (string, bool) {
+	if keyword := hcl.ExprAsKeyword(expr); keyword != "" {
+		return keyword, true
+	}
+	return literalString(expr)
+}
+
+// literalString returns the value of a string expression without references.
+func literalString(expr hclsyntax.Expression) (string, bool) {
+	if len(expr.Variables()) > 0 {
+		return "", false
+	}
+	value, diags := expr.Value(nil)
+	if diags.HasErrors() || value.IsNull() || !value.IsKnown() || value.Type() != cty.String {
+		return "", false
+	}
+	return value.AsString(), true
+}
+
+// expressionTokens parses an expression to the tokens hclwrite sets an attribute to.
+func expressionTokens(expr string) (hclwrite.Tokens, error) {
+	file, diags := hclwrite.ParseConfig([]byte("body = "+expr+"\n"), "body.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to rewrite body: %s", diags.Error())
+	}
+	return file.Body().GetAttribute("body").Expr().BuildTokens(nil), nil
+}
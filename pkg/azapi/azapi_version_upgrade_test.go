@@ -0,0 +1,156 @@
+package azapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const storageAccountBlock = `
+resource "azapi_resource" "this" {
+  type      = "Microsoft.Storage/storageAccounts@2021-01-01"
+  parent_id = var.resource_group_id
+  name      = "example"
+  location  = "westeurope"
+  body = {
+    kind = "StorageV2"
+    sku = {
+      name = "Standard_LRS"
+    }
+    properties = {
+      accessTier       = "Bogus"
+      primaryEndpoints = {}
+      foo              = var.foo
+      networkAcls = {
+        defaultAction = var.default_action
+        ipRules       = [{ value = "1.2.3.4" }]
+      }
+    }
+  }
+}
+`
+
+func TestCheckApiVersionUpgrade_InvalidProperties(t *testing.T) {
+	upgrade, err := CheckApiVersionUpgrade(context.Background(), storageAccountBlock, "2023-01-01")
+	require.NoError(t, err)
+	assert.Equal(t, "Microsoft.Storage/storageAccounts@2023-01-01", upgrade.Type)
+	assert.Equal(t, []UpgradeFinding{
+		{Path: "body.properties.accessTier", Message: `value "Bogus" is not allowed in api-version 2023-01-01, possible values: Hot, Cool, Premium`},
+		{Path: "body.properties.primaryEndpoints", Message: "read-only in api-version 2023-01-01, it's removed"},
+		{Path: "body.properties.foo", Message: "not a property in api-version 2023-01-01, it's removed"},
+	}, upgrade.Invalid)
+	assert.Empty(t, upgrade.Required)
+
+	assert.Contains(t, upgrade.Block, `type      = "Microsoft.Storage/storageAccounts@2023-01-01"`)
+	assert.Contains(t, upgrade.Block, `accessTier = "Bogus" # TODO: not allowed in api-version 2023-01-01`)
+	assert.Contains(t, upgrade.Block, "defaultAction = var.default_action")
+	assert.Contains(t, upgrade.Block, `value = "1.2.3.4"`)
+	assert.NotContains(t, upgrade.Block, "primaryEndpoints")
+	assert.NotContains(t, upgrade.Block, "var.foo")
+}
+
+func TestCheckApiVersionUpgrade_RemovedValues(t *testing.T) {
+	src := `
+resource "azapi_resource" "this" {
+  type = "Microsoft.ContainerService/managedClusters@2024-10-02-preview"
+  body = jsonencode({
+    properties = {
+      dnsPrefix           = "example"
+      publicNetworkAccess = "SecuredByPerimeter"
+    }
+  })
+}
+`
+	upgrade, err := CheckApiVersionUpgrade(context.Background(), src, "2025-01-01")
+	require.NoError(t, err)
+	require.Len(t, upgrade.RemovedValues, 1)
+	assert.Equal(t, "body.properties.publicNetworkAccess", upgrade.RemovedValues[0].Path)
+	assert.Contains(t, upgrade.RemovedValues[0].Message, "SecuredByPerimeter")
+	// The new enum is extensible, the value is only reported as removed.
+	assert.Empty(t, upgrade.Invalid)
+	assert.Contains(t, upgrade.Block, "body = jsonencode({")
+}
+
+func TestCheckApiVersionUpgrade_InvalidArrayItem(t *testing.T) {
+	src := `
+resource "azapi_resource" "this" {
+  type = "Microsoft.ServiceBus/namespaces/AuthorizationRules@2021-11-01"
+  body = {
+    properties = {
+      rights = ["Removed", "Send"]
+    }
+  }
+}
+`
+	upgrade, err := CheckApiVersionUpgrade(context.Background(), src, "2024-01-01")
+	require.NoError(t, err)
+	require.Len(t, upgrade.Invalid, 1)
+	assert.Equal(t, "body.properties.rights", upgrade.Invalid[0].Path)
+	assert.Contains(t, upgrade.Block, `"Removed", # TODO: not allowed in api-version 2024-01-01`)
+	assert.Contains(t, upgrade.Block, `"Send"`)
+}
+
+func TestCheckApiVersionUpgrade_NewlyRequired(t *testing.T) {
+	src := `
+resource "azapi_resource" "this" {
+  type = "Microsoft.Storage/storageAccounts@2015-05-01-preview"
+  body = {
+    properties = {}
+  }
+}
+`
+	upgrade, err := CheckApiVersionUpgrade(context.Background(), src, "2015-06-15")
+	require.NoError(t, err)
+	assert.Contains(t, upgrade.Required, UpgradeFinding{Path: "body.properties.accountType", Message: "newly required in api-version 2015-06-15"})
+	assert.Contains(t, upgrade.Block, "accountType = null # TODO: newly required in api-version 2015-06-15")
+}
+
+func TestCheckApiVersionUpgrade_KeepsComments(t *testing.T) {
+	src := `
+resource "azapi_resource" "this" {
+  type = "Microsoft.Storage/storageAccounts@2021-01-01"
+  body = {
+    # The kind of the account.
+    kind = "StorageV2"
+    properties = {
+      // Cool is cheaper.
+      accessTier = "Cool" # cold data
+      # foo isn't a property.
+      foo = var.foo
+      networkAcls = {
+        ipRules = [
+          /* office */ { value = "1.2.3.4" },
+          { value = "5.6.7.8" }, # home
+        ]
+      }
+      # end of properties
+    }
+  }
+}
+`
+	upgrade, err := CheckApiVersionUpgrade(context.Background(), src, "2023-01-01")
+	require.NoError(t, err)
+	for _, comment := range []string{"# The kind of the account.", "// Cool is cheaper.", "# cold data", "/* office */", "# home", "# end of properties"} {
+		assert.Contains(t, upgrade.Block, comment)
+	}
+	assert.Contains(t, upgrade.Block, `accessTier = "Cool" # cold data`)
+	// The comments of a removed property are removed with it.
+	assert.NotContains(t, upgrade.Block, "foo")
+}
+
+func TestCheckApiVersionUpgrade_NoBlock(t *testing.T) {
+	_, err := CheckApiVersionUpgrade(context.Background(), `resource "azapi_update_resource" "this" {}`, "2023-01-01")
+	assert.ErrorContains(t, err, "no azapi_resource block found")
+}
+
+func TestCheckApiVersionUpgrade_NonLiteralType(t *testing.T) {
+	src := `
+resource "azapi_resource" "this" {
+  type = "Microsoft.Storage/storageAccounts@${var.api_version}"
+}
+`
+	_, err := CheckApiVersionUpgrade(context.Background(), src, "2023-01-01")
+	assert.ErrorContains(t, err, "must be a literal string")
+}
@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ErrToolFailed is returned by Query when the tool reported an error result, which has already been printed.
+var ErrToolFailed = errors.New("tool failed")
+
+// Query runs a tool of server from the command line: args are the tool name followed by one flag per tool argument,
+// e.g. `list_azapi_api_versions -resource_type Microsoft.Compute/virtualMachines`.
+// The tool is called through an in-memory MCP session, so it behaves exactly as it does for MCP clients.
+// ctx is the context of the session, it must carry the dependencies of the tools.
+func Query(ctx context.Context, server *mcp.Server, args []string, stdout, stderr io.Writer) error {
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	ss, err := server.Connect(ctx, serverTransport)
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer ss.Close()
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "terraform-mcp-eva-cli"}, nil).Connect(ctx, clientTransport)
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer cs.Close()
+
+	var tools []*mcp.Tool
+	for tool, err := range cs.Tools(ctx, nil) {
+		if err != nil {
+			return fmt.Errorf("failed to list tools: %w", err)
+		}
+		tools = append(tools, tool)
+	}
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		printTools(stderr, tools)
+		if len(args) == 0 {
+			return fmt.Errorf("missing tool name")
+		}
+		return nil
+	}
+	name := strings.ReplaceAll(args[0], "-", "_")
+	i := slices.IndexFunc(tools, func(t *mcp.Tool) bool { return t.Name == name })
+	if i < 0 {
+		printTools(stderr, tools)
+		return fmt.Errorf("unknown tool %q", args[0])
+	}
+	arguments, err := parseArguments(tools[i], args[1:], stderr)
+	if err != nil {
+		return err
+	}
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: name, Arguments: arguments})
+	if err != nil {
+		return fmt.Errorf("failed to call tool %s: %w", name, err)
+	}
+	out := stdout
+	if res.IsError {
+		out = stderr
+	}
+	for _, c := range res.Content {
+		if text, ok := c.(*mcp.TextContent); ok {
+			fmt.Fprintln(out, text.Text)
+		}
+	}
+	if res.IsError {
+		return ErrToolFailed
+	}
+	return nil
+}
+
+// parseArguments turns the flags following the tool name into tool arguments, there is one flag per property of the input schema.
+func parseArguments(tool *mcp.Tool, args []string, stderr io.Writer) (map[string]any, error) {
+	fs := flag.NewFlagSet(tool.Name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: query %s [flags]\n\n%s\n\nFlags:\n", tool.Name, tool.Description)
+		fs.PrintDefaults()
+	}
+	schema := tool.InputSchema
+	if schema == nil {
+		schema = &jsonschema.Schema{}
+	}
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		property := schema.Properties[name]
+		usage := property.Description
+		if slices.Contains(schema.Required, name) {
+			usage += " (required)"
+		}
+		fs.Var(&argument{schema: property}, name, usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments %s", strings.Join(fs.Args(), " "))
+	}
+	arguments := make(map[string]any)
+	fs.Visit(func(f *flag.Flag) {
+		arguments[f.Name] = f.Value.(*argument).value
+	})
+	return arguments, nil
+}
+
+// argument is the flag of a tool argument, it converts the command line value to the JSON type of the property.
+type argument struct {
+	schema *jsonschema.Schema
+	value  any
+}
+
+func (a *argument) String() string {
+	if a == nil || a.value == nil {
+		return ""
+	}
+	return fmt.Sprint(a.value)
+}
+
+func (a *argument) Set(s string) error {
+	switch a.schema.Type {
+	case "integer":
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		a.value = i
+	case "number":
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		a.value = f
+	case "boolean":
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		a.value = b
+	case "array":
+		a.value = strings.Split(s, ",")
+	default:
+		a.value = s
+	}
+	return nil
+}
+
+func (a *argument) IsBoolFlag() bool {
+	return a.schema.Type == "boolean"
+}
+
+func printTools(w io.Writer, tools []*mcp.Tool) {
+	fmt.Fprintln(w, "Usage: query <tool> [flags], run `query <tool> -h` for the flags of a tool.\n\nTools:")
+	for _, t := range tools {
+		fmt.Fprintf(w, "  %s\n", t.Name)
+	}
+}
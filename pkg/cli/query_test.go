@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type greetParam struct {
+	Name   string `json:"name" jsonschema:"who to greet"`
+	Times  int    `json:"times,omitempty" jsonschema:"how often"`
+	Shout  bool   `json:"shout,omitempty" jsonschema:"upper case"`
+	Prefix string `json:"prefix,omitempty"`
+}
+
+type dependencyKey struct{}
+
+func newServer() *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "greet", Description: "Greets someone."}, func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[greetParam]) (*mcp.CallToolResultFor[any], error) {
+		if params.Arguments.Name == "" {
+			return nil, fmt.Errorf("name is required")
+		}
+		greeting := fmt.Sprintf("%s%s %s", params.Arguments.Prefix, ctx.Value(dependencyKey{}), params.Arguments.Name)
+		if params.Arguments.Shout {
+			greeting = strings.ToUpper(greeting)
+		}
+		return &mcp.CallToolResultFor[any]{
+			Content: []mcp.Content{&mcp.TextContent{Text: strings.Repeat(greeting+"\n", max(params.Arguments.Times, 1))}},
+		}, nil
+	})
+	return server
+}
+
+func query(t *testing.T, args ...string) (string, string, error) {
+	t.Helper()
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	ctx := context.WithValue(context.Background(), dependencyKey{}, "hello")
+	err := Query(ctx, newServer(), args, stdout, stderr)
+	return stdout.String(), stderr.String(), err
+}
+
+func TestQuery_CallsTool(t *testing.T) {
+	stdout, _, err := query(t, "greet", "-name", "world", "-times", "2", "-shout")
+	require.NoError(t, err)
+	assert.Equal(t, "HELLO WORLD\nHELLO WORLD\n\n", stdout)
+}
+
+func TestQuery_ToolError(t *testing.T) {
+	stdout, stderr, err := query(t, "greet")
+	require.ErrorIs(t, err, ErrToolFailed)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, "name is required")
+}
+
+func TestQuery_InvalidFlags(t *testing.T) {
+	_, _, err := query(t, "greet", "-times", "many")
+	require.Error(t, err)
+	_, _, err = query(t, "greet", "-nickname", "x")
+	require.Error(t, err)
+	_, _, err = query(t, "greet", "-name", "world", "extra")
+	require.ErrorContains(t, err, "unexpected arguments extra")
+}
+
+func TestQuery_UnknownTool(t *testing.T) {
+	_, stderr, err := query(t, "wave")
+	require.ErrorContains(t, err, `unknown tool "wave"`)
+	assert.Contains(t, stderr, "  greet\n")
+}
+
+func TestQuery_ToolHelp(t *testing.T) {
+	_, stderr, err := query(t, "greet", "-h")
+	require.Error(t, err)
+	assert.Contains(t, stderr, "Greets someone.")
+	assert.Contains(t, stderr, "who to greet (required)")
+}
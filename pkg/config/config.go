@@ -0,0 +1,438 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/auth"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/httpserver"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/logging"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/metrics"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/tfprovider"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/toolcall"
+	"gopkg.in/yaml.v3"
+)
+
+// ModeStdio serves MCP over stdin and stdout, the other modes are the httpserver.ModeXxx constants.
+const ModeStdio = "stdio"
+
+// Config is the server configuration. Values are taken from the defaults, then a YAML file,
+// then environment variables and finally command line flags, each overriding the previous one.
+type Config struct {
+	Transport Transport `yaml:"transport"`
+	Auth      Auth      `yaml:"auth"`
+	Cache     Cache     `yaml:"cache"`
+	Tools     Tools     `yaml:"tools"`
+	Prompts   Prompts   `yaml:"prompts"`
+	Providers Providers `yaml:"providers"`
+	Output    Output    `yaml:"output"`
+	Logging   Logging   `yaml:"logging"`
+	Metrics   Metrics   `yaml:"metrics"`
+	Tracing   Tracing   `yaml:"tracing"`
+}
+
+type Transport struct {
+	// Mode is stdio or one of the http modes: streamable-http, sse or http (both on the same listener).
+	Mode               string        `yaml:"mode"`
+	Host               string        `yaml:"host"`
+	Port               string        `yaml:"port"`
+	StreamableHTTPPath string        `yaml:"streamable_http_path"`
+	SSEPath            string        `yaml:"sse_path"`
+	TLSCertFile        string        `yaml:"tls_cert_file,omitempty"`
+	TLSKeyFile         string        `yaml:"tls_key_file,omitempty"`
+	ReadTimeout        time.Duration `yaml:"read_timeout"`
+	WriteTimeout       time.Duration `yaml:"write_timeout"`
+	IdleTimeout        time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout    time.Duration `yaml:"shutdown_timeout"`
+}
+
+type Auth struct {
+	TokensFile string `yaml:"tokens_file,omitempty"`
+	// Tokens are static bearer tokens, in addition to those of TokensFile.
+	Tokens   []auth.StaticToken `yaml:"tokens,omitempty"`
+	JWKSFile string             `yaml:"jwks_file,omitempty"`
+	JWKSURL  string             `yaml:"jwks_url,omitempty"`
+	Issuer   string             `yaml:"issuer,omitempty"`
+	Audience string             `yaml:"audience,omitempty"`
+	// RequiredScopes must all be granted by JWT access tokens.
+	RequiredScopes      []string `yaml:"required_scopes,omitempty"`
+	Resource            string   `yaml:"resource,omitempty"`
+	AuthorizationServer string   `yaml:"authorization_server,omitempty"`
+}
+
+type Cache struct {
+	// Dir is where provider binaries are downloaded and extracted, the system temporary directory when empty.
+	// It's set as the temporary directory of the whole process, TMPDIR or TMP on Windows, so other temporary files go there too.
+	Dir string `yaml:"dir,omitempty"`
+}
+
+type Tools struct {
+	// Groups lists the tool groups to register, e.g. azapi or terraform-provider.
+	Groups []string `yaml:"groups,omitempty"`
+	// Enabled lists tools registered in addition to those of Groups, all tools are registered when both are empty.
+	Enabled []string `yaml:"enabled,omitempty"`
+	// Disabled lists tools left out even when their group is enabled.
+	Disabled []string `yaml:"disabled,omitempty"`
+}
+
+type Prompts struct {
+	// Enabled lists the prompts to register, all prompts are registered when it's empty.
+	Enabled  []string `yaml:"enabled,omitempty"`
+	Disabled []string `yaml:"disabled,omitempty"`
+}
+
+type Providers struct {
+	// Prefetch lists `[hostname/]namespace/type[@version]` providers downloaded at startup.
+	Prefetch []string `yaml:"prefetch,omitempty"`
+	// Mirrors replace the provider_installation block of the Terraform CLI configuration when set.
+	Mirrors []Mirror `yaml:"mirrors,omitempty"`
+	// WorkingDirRoots are the directories the `working_dir` of schema queries must be in with the HTTP transports,
+	// which don't accept any `working_dir` when it's empty. The stdio transport and query mode accept all directories.
+	WorkingDirRoots []string `yaml:"working_dir_roots,omitempty"`
+}
+
+// Mirror is a provider installation method, see tfprovider.InstallationMethod.
+type Mirror struct {
+	// Kind is one of direct, filesystem_mirror or network_mirror.
+	Kind     string   `yaml:"kind"`
+	Location string   `yaml:"location,omitempty"`
+	Include  []string `yaml:"include,omitempty"`
+	Exclude  []string `yaml:"exclude,omitempty"`
+}
+
+// Output limits the size of tool results, 0 turns a limit off.
+type Output struct {
+	// MaxBytes truncates larger results.
+	MaxBytes int `yaml:"max_bytes"`
+	// MaxDepth collapses objects nested deeper in AzAPI schemas to {...}.
+	MaxDepth int `yaml:"max_depth"`
+	// PageSize is the number of items per page of long lists such as API versions.
+	PageSize int `yaml:"page_size"`
+}
+
+type Logging struct {
+	Level    string `yaml:"level"`
+	Format   string `yaml:"format"`
+	File     string `yaml:"file,omitempty"`
+	ToClient bool   `yaml:"to_client"`
+}
+
+type Metrics struct {
+	// Path of the Prometheus endpoint of the http server, empty to disable it.
+	Path string `yaml:"path"`
+}
+
+type Tracing struct {
+	// OTLPEndpoint is the OTLP/HTTP endpoint traces are exported to, empty to disable tracing.
+	OTLPEndpoint string `yaml:"otlp_endpoint,omitempty"`
+}
+
+// Default returns the configuration used when nothing else is set.
+func Default() *Config {
+	return &Config{
+		Transport: Transport{
+			Mode:               ModeStdio,
+			Host:               "127.0.0.1",
+			Port:               "8080",
+			StreamableHTTPPath: httpserver.DefaultStreamableHTTPPath,
+			SSEPath:            httpserver.DefaultSSEPath,
+			ReadTimeout:        httpserver.DefaultReadTimeout,
+			IdleTimeout:        httpserver.DefaultIdleTimeout,
+			ShutdownTimeout:    httpserver.DefaultShutdownTimeout,
+		},
+		Logging: Logging{
+			Level:  "warn",
+			Format: logging.FormatText,
+		},
+		Metrics: Metrics{
+			Path: metrics.DefaultPath,
+		},
+		Output: Output{
+			MaxBytes: toolcall.DefaultMaxBytes,
+			MaxDepth: toolcall.DefaultMaxDepth,
+			PageSize: toolcall.DefaultPageSize,
+		},
+	}
+}
+
+// Load reads a YAML configuration file on top of the defaults. Unknown keys are rejected.
+func Load(path string) (*Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	c := Default()
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err = decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// env maps the environment variables that override the configuration to their fields.
+var env = []struct {
+	name string
+	set  func(c *Config, value string) error
+}{
+	{"TRANSPORT_MODE", setString(func(c *Config) *string { return &c.Transport.Mode })},
+	{"TRANSPORT_HOST", setString(func(c *Config) *string { return &c.Transport.Host })},
+	{"TRANSPORT_PORT", setString(func(c *Config) *string { return &c.Transport.Port })},
+	{"TRANSPORT_STREAMABLE_HTTP_PATH", setString(func(c *Config) *string { return &c.Transport.StreamableHTTPPath })},
+	{"TRANSPORT_SSE_PATH", setString(func(c *Config) *string { return &c.Transport.SSEPath })},
+	{"TLS_CERT_FILE", setString(func(c *Config) *string { return &c.Transport.TLSCertFile })},
+	{"TLS_KEY_FILE", setString(func(c *Config) *string { return &c.Transport.TLSKeyFile })},
+	{"HTTP_READ_TIMEOUT", setDuration(func(c *Config) *time.Duration { return &c.Transport.ReadTimeout })},
+	{"HTTP_WRITE_TIMEOUT", setDuration(func(c *Config) *time.Duration { return &c.Transport.WriteTimeout })},
+	{"HTTP_IDLE_TIMEOUT", setDuration(func(c *Config) *time.Duration { return &c.Transport.IdleTimeout })},
+	{"HTTP_SHUTDOWN_TIMEOUT", setDuration(func(c *Config) *time.Duration { return &c.Transport.ShutdownTimeout })},
+	{"AUTH_TOKENS_FILE", setString(func(c *Config) *string { return &c.Auth.TokensFile })},
+	{"AUTH_TOKENS", func(c *Config, value string) error {
+		c.Auth.Tokens = auth.ParseTokens(value)
+		return nil
+	}},
+	{"AUTH_JWKS_FILE", setString(func(c *Config) *string { return &c.Auth.JWKSFile })},
+	{"AUTH_JWKS_URL", setString(func(c *Config) *string { return &c.Auth.JWKSURL })},
+	{"AUTH_ISSUER", setString(func(c *Config) *string { return &c.Auth.Issuer })},
+	{"AUTH_AUDIENCE", setString(func(c *Config) *string { return &c.Auth.Audience })},
+	{"AUTH_REQUIRED_SCOPES", setList(func(c *Config) *[]string { return &c.Auth.RequiredScopes })},
+	{"AUTH_RESOURCE", setString(func(c *Config) *string { return &c.Auth.Resource })},
+	{"AUTH_AUTHORIZATION_SERVER", setString(func(c *Config) *string { return &c.Auth.AuthorizationServer })},
+	{"CACHE_DIR", setString(func(c *Config) *string { return &c.Cache.Dir })},
+	{"TOOLS_GROUPS", setList(func(c *Config) *[]string { return &c.Tools.Groups })},
+	{"TOOLS_ENABLED", setList(func(c *Config) *[]string { return &c.Tools.Enabled })},
+	{"TOOLS_DISABLED", setList(func(c *Config) *[]string { return &c.Tools.Disabled })},
+	{"PROMPTS_ENABLED", setList(func(c *Config) *[]string { return &c.Prompts.Enabled })},
+	{"PROMPTS_DISABLED", setList(func(c *Config) *[]string { return &c.Prompts.Disabled })},
+	{"PREFETCH_PROVIDERS", setList(func(c *Config) *[]string { return &c.Providers.Prefetch })},
+	{"WORKING_DIR_ROOTS", setList(func(c *Config) *[]string { return &c.Providers.WorkingDirRoots })},
+	{"OUTPUT_MAX_BYTES", setInt(func(c *Config) *int { return &c.Output.MaxBytes })},
+	{"OUTPUT_MAX_DEPTH", setInt(func(c *Config) *int { return &c.Output.MaxDepth })},
+	{"OUTPUT_PAGE_SIZE", setInt(func(c *Config) *int { return &c.Output.PageSize })},
+	{"LOG_LEVEL", setString(func(c *Config) *string { return &c.Logging.Level })},
+	{"LOG_FORMAT", setString(func(c *Config) *string { return &c.Logging.Format })},
+	{"LOG_FILE", setString(func(c *Config) *string { return &c.Logging.File })},
+	{"LOG_TO_CLIENT", setBool(func(c *Config) *bool { return &c.Logging.ToClient })},
+	{"METRICS_PATH", setString(func(c *Config) *string { return &c.Metrics.Path })},
+	{"OTEL_EXPORTER_OTLP_ENDPOINT", setString(func(c *Config) *string { return &c.Tracing.OTLPEndpoint })},
+}
+
+// ApplyEnv overrides the configuration with the environment variables lookup finds, usually os.LookupEnv.
+func (c *Config) ApplyEnv(lookup func(string) (string, bool)) error {
+	var errs []error
+	for _, e := range env {
+		value, ok := lookup(e.name)
+		if !ok {
+			continue
+		}
+		if err := e.set(c, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", e.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func setString(field func(*Config) *string) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}
+}
+
+func setList(field func(*Config) *[]string) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		*field(c) = SplitList(value)
+		return nil
+	}
+}
+
+func setBool(field func(*Config) *bool) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		*field(c) = b
+		return nil
+	}
+}
+
+func setInt(field func(*Config) *int) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		*field(c) = i
+		return nil
+	}
+}
+
+func setDuration(field func(*Config) *time.Duration) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", value)
+		}
+		*field(c) = d
+		return nil
+	}
+}
+
+// SplitList splits a comma separated list, dropping empty items.
+func SplitList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// Validate reports every invalid setting at once, each error names the offending key.
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(key, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	switch c.Transport.Mode {
+	case ModeStdio, httpserver.ModeStreamableHTTP, httpserver.ModeSSE, httpserver.ModeHTTP:
+	default:
+		fail("transport.mode", "unknown mode %q, expected one of %s, %s, %s or %s", c.Transport.Mode, ModeStdio, httpserver.ModeStreamableHTTP, httpserver.ModeSSE, httpserver.ModeHTTP)
+	}
+	if port, err := strconv.Atoi(c.Transport.Port); err != nil || port < 0 || port > 65535 {
+		fail("transport.port", "invalid port %q", c.Transport.Port)
+	}
+	for _, p := range []struct{ key, path string }{
+		{"transport.streamable_http_path", c.Transport.StreamableHTTPPath},
+		{"transport.sse_path", c.Transport.SSEPath},
+	} {
+		if !strings.HasPrefix(p.path, "/") {
+			fail(p.key, "path %q must start with /", p.path)
+		}
+	}
+	if (c.Transport.TLSCertFile == "") != (c.Transport.TLSKeyFile == "") {
+		fail("transport.tls_cert_file", "tls_cert_file and tls_key_file must be set together")
+	}
+	for _, t := range []struct {
+		key string
+		d   time.Duration
+	}{
+		{"transport.read_timeout", c.Transport.ReadTimeout},
+		{"transport.write_timeout", c.Transport.WriteTimeout},
+		{"transport.idle_timeout", c.Transport.IdleTimeout},
+		{"transport.shutdown_timeout", c.Transport.ShutdownTimeout},
+	} {
+		if t.d < 0 {
+			fail(t.key, "duration %s must not be negative", t.d)
+		}
+	}
+
+	for i, t := range c.Auth.Tokens {
+		if t.Token == "" {
+			fail(fmt.Sprintf("auth.tokens[%d].token", i), "token must not be empty")
+		}
+	}
+	if c.Auth.JWKSFile != "" && c.Auth.JWKSURL != "" {
+		fail("auth.jwks_file", "jwks_file and jwks_url are mutually exclusive")
+	}
+	for _, u := range []struct{ key, url string }{
+		{"auth.jwks_url", c.Auth.JWKSURL},
+		{"auth.resource", c.Auth.Resource},
+	} {
+		if u.url == "" {
+			continue
+		}
+		if parsed, err := url.Parse(u.url); err != nil || parsed.Host == "" {
+			fail(u.key, "invalid URL %q", u.url)
+		}
+	}
+
+	for _, p := range c.Providers.Prefetch {
+		if _, err := tfprovider.ParsePrefetchList(p); err != nil {
+			fail("providers.prefetch", "invalid provider %q: %s", p, err)
+		}
+	}
+	for i, m := range c.Providers.Mirrors {
+		key := fmt.Sprintf("providers.mirrors[%d]", i)
+		switch m.Kind {
+		case tfprovider.InstallationDirect:
+		case tfprovider.InstallationFilesystemMirror, tfprovider.InstallationNetworkMirror:
+			if m.Location == "" {
+				fail(key+".location", "location is required for a %s", m.Kind)
+			}
+		default:
+			fail(key+".kind", "unknown kind %q, expected one of %s, %s or %s", m.Kind, tfprovider.InstallationDirect, tfprovider.InstallationFilesystemMirror, tfprovider.InstallationNetworkMirror)
+		}
+	}
+
+	for _, o := range []struct {
+		key   string
+		value int
+	}{
+		{"output.max_bytes", c.Output.MaxBytes},
+		{"output.max_depth", c.Output.MaxDepth},
+		{"output.page_size", c.Output.PageSize},
+	} {
+		if o.value < 0 {
+			fail(o.key, "must not be negative")
+		}
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Logging.Level)); err != nil {
+		fail("logging.level", "unknown level %q, expected one of debug, info, warn or error", c.Logging.Level)
+	}
+	if f := strings.ToLower(c.Logging.Format); f != logging.FormatText && f != logging.FormatJSON {
+		fail("logging.format", "unknown format %q, expected %s or %s", c.Logging.Format, logging.FormatText, logging.FormatJSON)
+	}
+	if c.Metrics.Path != "" && !strings.HasPrefix(c.Metrics.Path, "/") {
+		fail("metrics.path", "path %q must start with /", c.Metrics.Path)
+	}
+	if c.Tracing.OTLPEndpoint != "" {
+		if parsed, err := url.Parse(c.Tracing.OTLPEndpoint); err != nil || parsed.Host == "" {
+			fail("tracing.otlp_endpoint", "invalid URL %q, expected e.g. http://localhost:4318", c.Tracing.OTLPEndpoint)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// InstallationMethods converts the mirrors for the tfprovider.Registry.
+func (p Providers) InstallationMethods() []tfprovider.InstallationMethod {
+	var methods []tfprovider.InstallationMethod
+	for _, m := range p.Mirrors {
+		methods = append(methods, tfprovider.InstallationMethod{
+			Kind:     m.Kind,
+			Location: m.Location,
+			Include:  m.Include,
+			Exclude:  m.Exclude,
+		})
+	}
+	return methods
+}
+
+// WriteYAML writes the configuration as YAML with secrets redacted, as printed by --print-config.
+func (c *Config) WriteYAML(w io.Writer) error {
+	redacted := *c
+	redacted.Auth.Tokens = make([]auth.StaticToken, len(c.Auth.Tokens))
+	for i, t := range c.Auth.Tokens {
+		t.Token = "REDACTED"
+		redacted.Auth.Tokens[i] = t
+	}
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&redacted); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	return encoder.Close()
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/auth"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/tfprovider"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/toolcall"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestLoad_OverridesDefaults(t *testing.T) {
+	c, err := Load(writeConfig(t, `
+transport:
+  mode: http
+  read_timeout: 10s
+auth:
+  tokens:
+    - token: s3cr3t
+      subject: ci
+      tools: [list_azapi_api_versions]
+providers:
+  prefetch: [Azure/azapi@2.5.0]
+  mirrors:
+    - kind: filesystem_mirror
+      location: /opt/mirror
+output:
+  max_bytes: 4096
+`))
+	require.NoError(t, err)
+	require.NoError(t, c.Validate())
+	assert.Equal(t, "http", c.Transport.Mode)
+	assert.Equal(t, 10*time.Second, c.Transport.ReadTimeout)
+	assert.Equal(t, Default().Transport.IdleTimeout, c.Transport.IdleTimeout)
+	assert.Equal(t, []auth.StaticToken{{Token: "s3cr3t", Subject: "ci", Tools: []string{"list_azapi_api_versions"}}}, c.Auth.Tokens)
+	assert.Equal(t, []string{"Azure/azapi@2.5.0"}, c.Providers.Prefetch)
+	assert.Equal(t, []tfprovider.InstallationMethod{{Kind: tfprovider.InstallationFilesystemMirror, Location: "/opt/mirror"}}, c.Providers.InstallationMethods())
+	assert.Equal(t, 4096, c.Output.MaxBytes)
+	assert.Equal(t, "warn", c.Logging.Level)
+}
+
+func TestLoad_EmptyFile(t *testing.T) {
+	c, err := Load(writeConfig(t, ""))
+	require.NoError(t, err)
+	assert.Equal(t, Default(), c)
+}
+
+func TestLoad_UnknownKey(t *testing.T) {
+	_, err := Load(writeConfig(t, "transport:\n  mdoe: http\n"))
+	require.ErrorContains(t, err, "field mdoe not found")
+}
+
+func TestApplyEnv(t *testing.T) {
+	c := Default()
+	env := map[string]string{
+		"TRANSPORT_PORT":       "9000",
+		"HTTP_WRITE_TIMEOUT":   "1m",
+		"AUTH_TOKENS":          "a, b",
+		"AUTH_REQUIRED_SCOPES": "mcp:read, mcp:write",
+		"PREFETCH_PROVIDERS":   "Azure/azapi,hashicorp/azurerm@~> 4.0",
+		"WORKING_DIR_ROOTS":    "/srv/modules,/home/ci",
+		"LOG_TO_CLIENT":        "true",
+		"OUTPUT_MAX_BYTES":     "100",
+		"OUTPUT_PAGE_SIZE":     "0",
+		"TOOLS_GROUPS":         "azapi",
+		"PROMPTS_DISABLED":     "solve_avm_issue",
+	}
+	require.NoError(t, c.ApplyEnv(func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}))
+	assert.Equal(t, "9000", c.Transport.Port)
+	assert.Equal(t, time.Minute, c.Transport.WriteTimeout)
+	assert.Equal(t, []auth.StaticToken{{Token: "a"}, {Token: "b"}}, c.Auth.Tokens)
+	assert.Equal(t, []string{"mcp:read", "mcp:write"}, c.Auth.RequiredScopes)
+	assert.Equal(t, []string{"Azure/azapi", "hashicorp/azurerm@~> 4.0"}, c.Providers.Prefetch)
+	assert.Equal(t, []string{"/srv/modules", "/home/ci"}, c.Providers.WorkingDirRoots)
+	assert.True(t, c.Logging.ToClient)
+	assert.Equal(t, 100, c.Output.MaxBytes)
+	assert.Equal(t, toolcall.DefaultMaxDepth, c.Output.MaxDepth)
+	assert.Zero(t, c.Output.PageSize)
+	assert.Equal(t, []string{"azapi"}, c.Tools.Groups)
+	assert.Equal(t, []string{"solve_avm_issue"}, c.Prompts.Disabled)
+}
+
+func TestApplyEnv_InvalidValues(t *testing.T) {
+	env := map[string]string{
+		"HTTP_READ_TIMEOUT": "soon",
+		"LOG_TO_CLIENT":     "maybe",
+	}
+	err := Default().ApplyEnv(func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	})
+	require.ErrorContains(t, err, `HTTP_READ_TIMEOUT: invalid duration "soon"`)
+	require.ErrorContains(t, err, `LOG_TO_CLIENT: invalid boolean "maybe"`)
+}
+
+func TestValidate_ReportsEveryError(t *testing.T) {
+	c := Default()
+	c.Transport.Mode = "grpc"
+	c.Transport.Port = "http"
+	c.Transport.TLSCertFile = "cert.pem"
+	c.Providers.Mirrors = []Mirror{{Kind: "s3"}, {Kind: tfprovider.InstallationNetworkMirror}}
+	c.Providers.Prefetch = []string{"a/b/c/d"}
+	c.Logging.Format = "xml"
+	c.Tracing.OTLPEndpoint = "localhost:4318"
+	c.Output.PageSize = -1
+
+	err := c.Validate()
+	require.Error(t, err)
+	for _, key := range []string{
+		"transport.mode:",
+		"transport.port:",
+		"transport.tls_cert_file:",
+		"providers.mirrors[0].kind:",
+		"providers.mirrors[1].location:",
+		"providers.prefetch:",
+		"logging.format:",
+		"tracing.otlp_endpoint:",
+		"output.page_size:",
+	} {
+		assert.Contains(t, err.Error(), key)
+	}
+	require.NoError(t, Default().Validate())
+}
+
+func TestWriteYAML_RedactsTokens(t *testing.T) {
+	c := Default()
+	c.Auth.Tokens = []auth.StaticToken{{Token: "s3cr3t", Subject: "ci"}}
+	buf := &bytes.Buffer{}
+	require.NoError(t, c.WriteYAML(buf))
+	assert.NotContains(t, buf.String(), "s3cr3t")
+	assert.Contains(t, buf.String(), "REDACTED")
+	assert.Equal(t, "s3cr3t", c.Auth.Tokens[0].Token)
+
+	path := writeConfig(t, buf.String())
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, c.Transport, loaded.Transport)
+}
@@ -0,0 +1,71 @@
+package httpserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// ModeStreamableHTTP serves the streamable HTTP transport of the 2025-03-26 MCP specification.
+	ModeStreamableHTTP = "streamable-http"
+	// ModeSSE serves the legacy HTTP+SSE transport of the 2024-11-05 MCP specification.
+	ModeSSE = "sse"
+	// ModeHTTP serves both transports from the same listener.
+	ModeHTTP = "http"
+
+	DefaultStreamableHTTPPath = "/mcp"
+	DefaultSSEPath            = "/sse"
+)
+
+// Options controls which MCP transports are served over HTTP and where.
+type Options struct {
+	Mode               string
+	StreamableHTTPPath string
+	SSEPath            string
+	// ContextValues decorates the context of every new MCP session, e.g. to add the dependencies tools look up with ctx.Value.
+	ContextValues func(context.Context) context.Context
+}
+
+// NewHandler returns an http.Handler serving server over the transports selected by opts.Mode.
+func NewHandler(server *mcp.Server, opts Options) (http.Handler, error) {
+	streamablePath := pathOrDefault(opts.StreamableHTTPPath, DefaultStreamableHTTPPath)
+	ssePath := pathOrDefault(opts.SSEPath, DefaultSSEPath)
+	getServer := func(*http.Request) *mcp.Server { return server }
+
+	mux := http.NewServeMux()
+	switch opts.Mode {
+	case ModeStreamableHTTP:
+		mux.Handle(streamablePath, mcp.NewStreamableHTTPHandler(getServer, nil))
+	case ModeSSE:
+		mux.Handle(ssePath, mcp.NewSSEHandler(getServer))
+	case ModeHTTP:
+		if streamablePath == ssePath {
+			return nil, fmt.Errorf("streamable HTTP and SSE transports can't share the path %s", ssePath)
+		}
+		mux.Handle(streamablePath, mcp.NewStreamableHTTPHandler(getServer, nil))
+		mux.Handle(ssePath, mcp.NewSSEHandler(getServer))
+	default:
+		return nil, fmt.Errorf("unknown HTTP transport mode %q, expected %s, %s or %s", opts.Mode, ModeStreamableHTTP, ModeSSE, ModeHTTP)
+	}
+	if opts.ContextValues == nil {
+		return mux, nil
+	}
+	// The MCP handlers connect new sessions with the request context, so values added here are visible to every tool call of the session.
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(w, r.WithContext(opts.ContextValues(r.Context())))
+	}), nil
+}
+
+func pathOrDefault(path, fallback string) string {
+	if path == "" {
+		return fallback
+	}
+	if !strings.HasPrefix(path, "/") {
+		return "/" + path
+	}
+	return path
+}
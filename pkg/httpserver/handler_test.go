@@ -0,0 +1,80 @@
+package httpserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type greetingKey struct{}
+
+type greetParam struct {
+	Name string `json:"name"`
+}
+
+func greet(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[greetParam]) (*mcp.CallToolResultFor[any], error) {
+	greeting, _ := ctx.Value(greetingKey{}).(string)
+	return &mcp.CallToolResultFor[any]{
+		Content: []mcp.Content{&mcp.TextContent{Text: greeting + " " + params.Arguments.Name}},
+	}, nil
+}
+
+func newTestServer(t *testing.T, opts Options) *httptest.Server {
+	t.Helper()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "greet"}, greet)
+	opts.ContextValues = func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, greetingKey{}, "hello")
+	}
+	handler, err := NewHandler(server, opts)
+	require.NoError(t, err)
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func callGreet(t *testing.T, transport mcp.Transport) {
+	t.Helper()
+	ctx := context.Background()
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil).Connect(ctx, transport)
+	require.NoError(t, err)
+	defer func() { _ = session.Close() }()
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "greet", Arguments: map[string]any{"name": "world"}})
+	require.NoError(t, err)
+	require.Len(t, result.Content, 1)
+	assert.Equal(t, "hello world", result.Content[0].(*mcp.TextContent).Text)
+}
+
+func TestNewHandler_StreamableHTTP(t *testing.T) {
+	srv := newTestServer(t, Options{Mode: ModeStreamableHTTP})
+	callGreet(t, mcp.NewStreamableClientTransport(srv.URL+DefaultStreamableHTTPPath, nil))
+
+	resp, err := http.Get(srv.URL + DefaultSSEPath)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestNewHandler_SSE(t *testing.T) {
+	srv := newTestServer(t, Options{Mode: ModeSSE, SSEPath: "events"})
+	callGreet(t, mcp.NewSSEClientTransport(srv.URL+"/events", nil))
+}
+
+func TestNewHandler_BothTransports(t *testing.T) {
+	srv := newTestServer(t, Options{Mode: ModeHTTP, StreamableHTTPPath: "/v1/mcp"})
+	callGreet(t, mcp.NewStreamableClientTransport(srv.URL+"/v1/mcp", nil))
+	callGreet(t, mcp.NewSSEClientTransport(srv.URL+DefaultSSEPath, nil))
+}
+
+func TestNewHandler_InvalidOptions(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	_, err := NewHandler(server, Options{Mode: "websocket"})
+	require.Error(t, err)
+	_, err = NewHandler(server, Options{Mode: ModeHTTP, StreamableHTTPPath: "/mcp", SSEPath: "/mcp"})
+	require.Error(t, err)
+}
@@ -0,0 +1,97 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+const (
+	HealthzPath = "/healthz"
+	ReadyzPath  = "/readyz"
+)
+
+// Health tracks the components that must be initialized before the server is ready to take traffic.
+type Health struct {
+	mu           sync.RWMutex
+	pending      map[string]error
+	shuttingDown bool
+}
+
+func NewHealth() *Health {
+	return &Health{pending: make(map[string]error)}
+}
+
+// Track registers a component that isn't ready yet, the returned func marks it ready when called with a nil error.
+// A component that failed keeps the server unready and its error is reported by the readiness endpoint.
+func (h *Health) Track(name string) func(error) {
+	h.mu.Lock()
+	h.pending[name] = errNotInitialized
+	h.mu.Unlock()
+	return func(err error) {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if err == nil {
+			delete(h.pending, name)
+			return
+		}
+		h.pending[name] = err
+	}
+}
+
+// SetShuttingDown makes the server unready so load balancers stop routing new sessions to it.
+func (h *Health) SetShuttingDown() {
+	h.mu.Lock()
+	h.shuttingDown = true
+	h.mu.Unlock()
+}
+
+type readiness struct {
+	Ready        bool              `json:"ready"`
+	ShuttingDown bool              `json:"shutting_down,omitempty"`
+	Pending      map[string]string `json:"pending,omitempty"`
+}
+
+func (h *Health) readiness() readiness {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	r := readiness{ShuttingDown: h.shuttingDown}
+	if len(h.pending) > 0 {
+		names := make([]string, 0, len(h.pending))
+		for name := range h.pending {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		r.Pending = make(map[string]string, len(names))
+		for _, name := range names {
+			r.Pending[name] = h.pending[name].Error()
+		}
+	}
+	r.Ready = !r.ShuttingDown && len(r.Pending) == 0
+	return r
+}
+
+// Register serves the liveness and readiness endpoints on mux.
+func (h *Health) Register(mux *http.ServeMux) {
+	mux.HandleFunc(HealthzPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte("ok"))
+	})
+	mux.HandleFunc(ReadyzPath, func(w http.ResponseWriter, r *http.Request) {
+		status := h.readiness()
+		w.Header().Set("Content-Type", "application/json")
+		if !status.Ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(status)
+	})
+}
+
+type healthError string
+
+func (e healthError) Error() string {
+	return string(e)
+}
+
+const errNotInitialized = healthError("not initialized yet")
@@ -0,0 +1,46 @@
+package httpserver
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getStatus(t *testing.T, mux *http.ServeMux, path string) (int, string) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w.Code, w.Body.String()
+}
+
+func TestHealth_Readiness(t *testing.T) {
+	health := NewHealth()
+	mux := http.NewServeMux()
+	health.Register(mux)
+	azapiDone := health.Track("azapi-types")
+	providersDone := health.Track("provider-schemas")
+
+	code, body := getStatus(t, mux, ReadyzPath)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.JSONEq(t, `{"ready":false,"pending":{"azapi-types":"not initialized yet","provider-schemas":"not initialized yet"}}`, body)
+
+	azapiDone(nil)
+	providersDone(errors.New("registry unreachable"))
+	code, body = getStatus(t, mux, ReadyzPath)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.JSONEq(t, `{"ready":false,"pending":{"provider-schemas":"registry unreachable"}}`, body)
+
+	providersDone(nil)
+	code, body = getStatus(t, mux, ReadyzPath)
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"ready":true}`, body)
+
+	health.SetShuttingDown()
+	code, _ = getStatus(t, mux, ReadyzPath)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	code, _ = getStatus(t, mux, HealthzPath)
+	assert.Equal(t, http.StatusOK, code, "liveness doesn't depend on readiness")
+}
@@ -0,0 +1,139 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Config holds the listener settings of the HTTP server.
+type Config struct {
+	Addr        string
+	TLSCertFile string
+	TLSKeyFile  string
+	// ReadTimeout bounds reading a whole request, including its body.
+	ReadTimeout time.Duration
+	// WriteTimeout bounds writing a response. SSE streams live as long as their session, so it should stay 0 unless only short requests are served.
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+	// ShutdownTimeout is how long in-flight requests are given to complete once shutdown starts, before connections are closed.
+	ShutdownTimeout time.Duration
+}
+
+const (
+	DefaultReadTimeout     = 30 * time.Second
+	DefaultIdleTimeout     = 120 * time.Second
+	DefaultShutdownTimeout = 30 * time.Second
+)
+
+// Server serves MCP over HTTP until its context is cancelled, then drains the active sessions.
+type Server struct {
+	config Config
+	http   *http.Server
+	mcp    *mcp.Server
+	health *Health
+}
+
+// NewServer validates cfg and prepares a server for handler, health may be nil.
+func NewServer(cfg Config, handler http.Handler, server *mcp.Server, health *Health) (*Server, error) {
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		return nil, fmt.Errorf("both a TLS certificate and a TLS key are required to serve TLS")
+	}
+	if cfg.ShutdownTimeout <= 0 {
+		cfg.ShutdownTimeout = DefaultShutdownTimeout
+	}
+	return &Server{
+		config: cfg,
+		http: &http.Server{
+			Addr:              cfg.Addr,
+			Handler:           handler,
+			ReadTimeout:       cfg.ReadTimeout,
+			ReadHeaderTimeout: cfg.ReadTimeout,
+			WriteTimeout:      cfg.WriteTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+		},
+		mcp:    server,
+		health: health,
+	}, nil
+}
+
+// ListenAndServe listens on the configured address and serves until ctx is done.
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	l, err := net.Listen("tcp", s.config.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.config.Addr, err)
+	}
+	return s.Serve(ctx, l)
+}
+
+// Serve serves on l until ctx is done, then shuts down gracefully.
+// The listener is closed first, MCP sessions are closed once their in-flight requests complete,
+// and whatever is still running after the shutdown timeout is cut off.
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		if s.config.TLSCertFile != "" {
+			serveErr <- s.http.ServeTLS(l, s.config.TLSCertFile, s.config.TLSKeyFile)
+			return
+		}
+		serveErr <- s.http.Serve(l)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	if s.health != nil {
+		s.health.SetShuttingDown()
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
+	defer cancel()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.closeSessions(shutdownCtx)
+	}()
+	err := s.http.Shutdown(shutdownCtx)
+	wg.Wait()
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = s.http.Close()
+	}
+	if serr := <-serveErr; !errors.Is(serr, http.ErrServerClosed) {
+		err = errors.Join(err, serr)
+	}
+	return err
+}
+
+// closeSessions ends the long-lived SSE and streamable HTTP streams, which http.Server.Shutdown would otherwise wait on until its deadline.
+// Closing a session waits for its in-flight requests.
+func (s *Server) closeSessions(ctx context.Context) {
+	if s.mcp == nil {
+		return
+	}
+	var wg sync.WaitGroup
+	for session := range s.mcp.Sessions() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = session.Close()
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,121 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSelfSignedCert(t *testing.T) (certFile, keyFile string, pool *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "tls.crt")
+	keyFile = filepath.Join(dir, "tls.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	pool = x509.NewCertPool()
+	pool.AddCert(cert)
+	return certFile, keyFile, pool
+}
+
+func startServer(t *testing.T, cfg Config, handler http.Handler, server *mcp.Server) (string, context.CancelFunc, <-chan error) {
+	t.Helper()
+	s, err := NewServer(cfg, handler, server, nil)
+	require.NoError(t, err)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(ctx, l) }()
+	t.Cleanup(cancel)
+	return l.Addr().String(), cancel, done
+}
+
+func TestServer_TLS(t *testing.T) {
+	certFile, keyFile, pool := writeSelfSignedCert(t)
+	mux := http.NewServeMux()
+	NewHealth().Register(mux)
+	addr, cancel, done := startServer(t, Config{TLSCertFile: certFile, TLSKeyFile: keyFile}, mux, nil)
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	resp, err := client.Get("https://" + addr + HealthzPath)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestNewServer_TLSRequiresCertAndKey(t *testing.T) {
+	_, err := NewServer(Config{TLSCertFile: "tls.crt"}, http.NotFoundHandler(), nil, nil)
+	require.Error(t, err)
+}
+
+func TestServer_GracefulShutdownDrainsSessions(t *testing.T) {
+	started := make(chan struct{})
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "slow"}, func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[struct{}]) (*mcp.CallToolResultFor[any], error) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		return &mcp.CallToolResultFor[any]{Content: []mcp.Content{&mcp.TextContent{Text: "done"}}}, nil
+	})
+	handler, err := NewHandler(server, Options{Mode: ModeStreamableHTTP})
+	require.NoError(t, err)
+	addr, cancel, done := startServer(t, Config{ShutdownTimeout: 5 * time.Second}, handler, server)
+
+	ctx := context.Background()
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil).Connect(ctx, mcp.NewStreamableClientTransport("http://"+addr+DefaultStreamableHTTPPath, nil))
+	require.NoError(t, err)
+	type callResult struct {
+		result *mcp.CallToolResult
+		err    error
+	}
+	called := make(chan callResult, 1)
+	go func() {
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "slow", Arguments: map[string]any{}})
+		called <- callResult{result, err}
+	}()
+	<-started
+
+	start := time.Now()
+	cancel()
+	require.NoError(t, <-done)
+	assert.Less(t, time.Since(start), 5*time.Second, "open sessions must not hold shutdown until its deadline")
+	call := <-called
+	require.NoError(t, call.err, "the in-flight tool call completes before its session is closed")
+	assert.Equal(t, "done", call.result.Content[0].(*mcp.TextContent).Text)
+}
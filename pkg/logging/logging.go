@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Options configures the server logger.
+type Options struct {
+	// Level is one of debug, info, warn or error.
+	Level string
+	// Format is FormatText or FormatJSON.
+	Format string
+	// File receives the logs when set, otherwise they go to the writer passed to New.
+	File string
+}
+
+// New creates the server logger. The returned closer releases the log file, if any.
+// In stdio mode w must not be stdout, which carries the MCP messages.
+func New(opts Options, w io.Writer) (*slog.Logger, io.Closer, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(opts.Level))); err != nil {
+		return nil, nil, fmt.Errorf("invalid log level %q: %w", opts.Level, err)
+	}
+	var closer io.Closer = io.NopCloser(nil)
+	if opts.File != "" {
+		f, err := os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open log file %s: %w", opts.File, err)
+		}
+		w = f
+		closer = f
+	}
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	switch strings.ToLower(opts.Format) {
+	case FormatText, "":
+		return slog.New(slog.NewTextHandler(w, handlerOpts)), closer, nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, handlerOpts)), closer, nil
+	default:
+		_ = closer.Close()
+		return nil, nil, fmt.Errorf("invalid log format %q, expected %s or %s", opts.Format, FormatText, FormatJSON)
+	}
+}
+
+type contextKey struct{}
+
+// WithLogger returns a context carrying l, tool code logs through it so its records are correlated with the call.
+func WithLogger(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger of the current tool call, or the default logger outside of one.
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
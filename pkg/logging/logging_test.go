@@ -0,0 +1,52 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_JSONToWriter(t *testing.T) {
+	var buf bytes.Buffer
+	l, closer, err := New(Options{Level: "info", Format: FormatJSON}, &buf)
+	require.NoError(t, err)
+	defer closer.Close()
+	l.Debug("hidden")
+	l.Info("shown", "key", "value")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "shown", record["msg"])
+	assert.Equal(t, "value", record["key"])
+}
+
+func TestNew_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "eva.log")
+	var buf bytes.Buffer
+	l, closer, err := New(Options{Level: "warn", File: path}, &buf)
+	require.NoError(t, err)
+	l.Warn("to the file")
+	require.NoError(t, closer.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "msg=\"to the file\"")
+	assert.Empty(t, buf.String())
+}
+
+func TestNew_InvalidOptions(t *testing.T) {
+	_, _, err := New(Options{Level: "verbose"}, &bytes.Buffer{})
+	require.Error(t, err)
+	_, _, err = New(Options{Level: "info", Format: "xml"}, &bytes.Buffer{})
+	require.Error(t, err)
+}
+
+func TestFromContext_DefaultLogger(t *testing.T) {
+	assert.NotNil(t, FromContext(context.Background()))
+}
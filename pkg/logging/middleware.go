@@ -0,0 +1,107 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/toolcall"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// LoggerName is the logger reported in MCP logging notifications.
+const LoggerName = "terraform-mcp-eva"
+
+// maxArgumentLength bounds how much of a single argument value ends up in the logs, lock files can be large.
+const maxArgumentLength = 80
+
+// ToolMiddleware logs one record per tool call with its session, arguments, duration and outcome.
+// Successful calls are logged at info level and failed ones at warn level.
+// When notifyClient is set the records are also sent to the client as MCP logging notifications,
+// at the level the client asked for with logging/setLevel.
+func ToolMiddleware(l *slog.Logger, notifyClient bool) toolcall.Middleware {
+	return func(next toolcall.Handler) toolcall.Handler {
+		return func(ctx context.Context, call *toolcall.Call) (*mcp.CallToolResult, error) {
+			var sessionID string
+			if call.Session != nil {
+				sessionID = call.Session.ID()
+			}
+			callLogger := l.With("call_id", newCallID(), "session_id", sessionID, "tool", call.Name)
+			var clientLogger *slog.Logger
+			if notifyClient && call.Session != nil {
+				clientLogger = slog.New(mcp.NewLoggingHandler(call.Session, &mcp.LoggingHandlerOptions{LoggerName: LoggerName})).With("tool", call.Name)
+			}
+
+			start := time.Now()
+			res, err := next(WithLogger(ctx, callLogger), call)
+			attrs := []any{
+				"arguments", SummarizeArguments(call.Arguments),
+				"duration", time.Since(start),
+			}
+			level, msg := slog.LevelInfo, "tool call succeeded"
+			switch {
+			case err != nil:
+				level, msg = slog.LevelWarn, "tool call failed"
+				attrs = append(attrs, "error", err.Error())
+			case res != nil && res.IsError:
+				level, msg = slog.LevelWarn, "tool call returned an error result"
+			}
+			callLogger.Log(ctx, level, msg, attrs...)
+			if clientLogger != nil {
+				clientLogger.Log(ctx, level, msg, attrs...)
+			}
+			return res, err
+		}
+	}
+}
+
+// SummarizeArguments renders tool arguments as compact `key=value` pairs, truncating long values and omitting empty ones.
+func SummarizeArguments(args any) string {
+	payload, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Sprintf("%T", args)
+	}
+	var fields map[string]any
+	if err = json.Unmarshal(payload, &fields); err != nil {
+		return truncate(string(payload))
+	}
+	keys := make([]string, 0, len(fields))
+	for k, v := range fields {
+		if v == nil || v == "" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		var value string
+		if s, ok := fields[k].(string); ok {
+			value = s
+		} else {
+			encoded, _ := json.Marshal(fields[k])
+			value = string(encoded)
+		}
+		parts = append(parts, fmt.Sprintf("%s=%q", k, truncate(value)))
+	}
+	return strings.Join(parts, " ")
+}
+
+func truncate(s string) string {
+	if len(s) <= maxArgumentLength {
+		return s
+	}
+	return fmt.Sprintf("%s...(%d bytes)", strings.ToValidUTF8(s[:maxArgumentLength], ""), len(s))
+}
+
+func newCallID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
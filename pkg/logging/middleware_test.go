@@ -0,0 +1,115 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/toolcall"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type queryParam struct {
+	ResourceType string `json:"resource_type"`
+	Path         string `json:"path,omitempty"`
+	LockFile     string `json:"lock_file,omitempty"`
+}
+
+func decodeRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestToolMiddleware_LogsCalls(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	handler := ToolMiddleware(l, false)(func(ctx context.Context, call *toolcall.Call) (*mcp.CallToolResult, error) {
+		FromContext(ctx).Debug("inside the tool")
+		if call.Arguments.(queryParam).ResourceType == "" {
+			return nil, errors.New("resource_type is required")
+		}
+		return &mcp.CallToolResult{}, nil
+	})
+
+	_, err := handler(context.Background(), &toolcall.Call{Name: "query", Arguments: queryParam{ResourceType: "Microsoft.Compute/virtualMachines"}})
+	require.NoError(t, err)
+	_, err = handler(context.Background(), &toolcall.Call{Name: "query", Arguments: queryParam{}})
+	require.Error(t, err)
+
+	records := decodeRecords(t, &buf)
+	require.Len(t, records, 4)
+	inside, succeeded := records[0], records[1]
+	assert.Equal(t, "inside the tool", inside["msg"])
+	assert.Equal(t, succeeded["call_id"], inside["call_id"], "records logged by the tool are correlated with the call")
+	assert.Equal(t, "INFO", succeeded["level"])
+	assert.Equal(t, "query", succeeded["tool"])
+	assert.Equal(t, `resource_type="Microsoft.Compute/virtualMachines"`, succeeded["arguments"])
+	assert.Contains(t, succeeded, "duration")
+
+	failed := records[3]
+	assert.Equal(t, "WARN", failed["level"])
+	assert.Equal(t, "resource_type is required", failed["error"])
+	assert.NotEqual(t, succeeded["call_id"], failed["call_id"])
+}
+
+func TestToolMiddleware_NotifiesClient(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "query"}, toolcall.Wrap("query", func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[queryParam]) (*mcp.CallToolResultFor[any], error) {
+		return &mcp.CallToolResultFor[any]{}, nil
+	}, ToolMiddleware(slog.New(slog.DiscardHandler), true)))
+
+	var mu sync.Mutex
+	var messages []*mcp.LoggingMessageParams
+	received := make(chan struct{}, 1)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, &mcp.ClientOptions{
+		LoggingMessageHandler: func(ctx context.Context, cs *mcp.ClientSession, params *mcp.LoggingMessageParams) {
+			mu.Lock()
+			messages = append(messages, params)
+			mu.Unlock()
+			received <- struct{}{}
+		},
+	})
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	_, err := server.Connect(ctx, serverTransport)
+	require.NoError(t, err)
+	session, err := client.Connect(ctx, clientTransport)
+	require.NoError(t, err)
+	defer func() { _ = session.Close() }()
+
+	require.NoError(t, session.SetLevel(ctx, &mcp.SetLevelParams{Level: "info"}))
+	_, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "query", Arguments: map[string]any{"resource_type": "Microsoft.Storage/storageAccounts"}})
+	require.NoError(t, err)
+	<-received
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, messages, 1)
+	assert.Equal(t, LoggerName, messages[0].Logger)
+	assert.Equal(t, mcp.LoggingLevel("info"), messages[0].Level)
+	data, err := json.Marshal(messages[0].Data)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Microsoft.Storage/storageAccounts")
+}
+
+func TestSummarizeArguments(t *testing.T) {
+	summary := SummarizeArguments(queryParam{
+		ResourceType: "Microsoft.Compute/virtualMachines",
+		LockFile:     strings.Repeat("a", 200),
+	})
+	assert.Equal(t, `lock_file="`+strings.Repeat("a", 80)+`...(200 bytes)" resource_type="Microsoft.Compute/virtualMachines"`, summary)
+	assert.Equal(t, "", SummarizeArguments(map[string]any{}))
+}
@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	namespace = "terraform_mcp_eva"
+
+	// DefaultPath is where the HTTP server exposes the metrics.
+	DefaultPath = "/metrics"
+
+	// CacheProviderSchema is the tfpluginschema cache of downloaded provider binaries.
+	CacheProviderSchema = "tfpluginschema"
+	// CacheAzAPITypes is the cache of AzAPI resource type definitions loaded from the embedded type files.
+	CacheAzAPITypes = "azapi_types"
+)
+
+// durationBuckets covers quick AzAPI lookups as well as provider downloads, which can take minutes.
+var durationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120}
+
+var (
+	registry = prometheus.NewRegistry()
+
+	toolCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tool_calls_total",
+		Help:      "Number of tool calls by tool and outcome.",
+	}, []string{"tool", "outcome"})
+	toolDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "tool_call_duration_seconds",
+		Help:      "Duration of tool calls.",
+		Buckets:   durationBuckets,
+	}, []string{"tool"})
+	toolErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tool_errors_total",
+		Help:      "Number of failed tool calls by tool and error class.",
+	}, []string{"tool", "class"})
+	providerDownloadDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "provider_download_duration_seconds",
+		Help:      "Duration of provider downloads, including extraction.",
+		Buckets:   durationBuckets,
+	}, []string{"provider", "outcome"})
+	cacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Number of cache lookups by cache and result (hit or miss).",
+	}, []string{"cache", "result"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		toolCalls,
+		toolDuration,
+		toolErrors,
+		providerDownloadDuration,
+		cacheRequests,
+	)
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
+
+// ObserveSessions exposes the number of sessions connected to server.
+func ObserveSessions(server *mcp.Server) error {
+	return registry.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_sessions",
+		Help:      "Number of connected MCP sessions.",
+	}, func() float64 {
+		var n int
+		for range server.Sessions() {
+			n++
+		}
+		return float64(n)
+	}))
+}
+
+// ObserveCache records a cache lookup.
+func ObserveCache(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	cacheRequests.WithLabelValues(cache, result).Inc()
+}
+
+// ObserveProviderDownload records how long downloading a provider took, provider is its `namespace/type`.
+func ObserveProviderDownload(provider string, d time.Duration, err error) {
+	providerDownloadDuration.WithLabelValues(provider, outcome(err == nil)).Observe(d.Seconds())
+}
+
+func outcome(ok bool) string {
+	if ok {
+		return "success"
+	}
+	return "error"
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/toolcall"
+	"github.com/matt-FFFFFF/tfpluginschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolMiddleware(t *testing.T) {
+	handler := ToolMiddleware(func(ctx context.Context, call *toolcall.Call) (*mcp.CallToolResult, error) {
+		switch call.Arguments {
+		case "bad":
+			return nil, fmt.Errorf("%w: resource_type is required", toolcall.ErrInvalidArgument)
+		case "flagged":
+			return &mcp.CallToolResult{IsError: true}, nil
+		}
+		return &mcp.CallToolResult{}, nil
+	})
+	for _, args := range []string{"ok", "ok", "bad", "flagged"} {
+		_, _ = handler(context.Background(), &toolcall.Call{Name: "test_tool", Arguments: args})
+	}
+	assert.Equal(t, 2.0, testutil.ToFloat64(toolCalls.WithLabelValues("test_tool", "success")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(toolCalls.WithLabelValues("test_tool", "error")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(toolErrors.WithLabelValues("test_tool", ErrorClassInvalidArgument)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(toolErrors.WithLabelValues("test_tool", ErrorClassToolResult)))
+	assert.Equal(t, 1, testutil.CollectAndCount(toolDuration, "terraform_mcp_eva_tool_call_duration_seconds"))
+}
+
+func TestErrorClass(t *testing.T) {
+	cases := map[string]struct {
+		err  error
+		want string
+	}{
+		"invalid argument": {fmt.Errorf("wrapped: %w", toolcall.ErrInvalidArgument), ErrorClassInvalidArgument},
+		"canceled":         {fmt.Errorf("wrapped: %w", context.Canceled), ErrorClassCanceled},
+		"deadline":         {context.DeadlineExceeded, ErrorClassTimeout},
+		"network timeout":  {&url.Error{Op: "Get", URL: "https://example.com", Err: os.ErrDeadlineExceeded}, ErrorClassTimeout},
+		"network":          {&url.Error{Op: "Get", URL: "https://example.com", Err: io.ErrUnexpectedEOF}, ErrorClassUpstream},
+		"plugin not found": {fmt.Errorf("failed: %w", tfpluginschema.ErrPluginNotFound), ErrorClassNotFound},
+		"plugin api":       {fmt.Errorf("failed: %w", tfpluginschema.ErrPluginApi), ErrorClassUpstream},
+		"other":            {errors.New("boom"), ErrorClassInternal},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, c.want, ErrorClass(c.err))
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	ObserveCache(CacheAzAPITypes, true)
+	ObserveCache(CacheAzAPITypes, false)
+	ObserveProviderDownload("Azure/azapi", 0, nil)
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	require.NoError(t, ObserveSessions(server))
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest("GET", DefaultPath, nil))
+	body := w.Body.String()
+	assert.Contains(t, body, `terraform_mcp_eva_cache_requests_total{cache="azapi_types",result="hit"} 1`)
+	assert.Contains(t, body, `terraform_mcp_eva_cache_requests_total{cache="azapi_types",result="miss"} 1`)
+	assert.Contains(t, body, `terraform_mcp_eva_provider_download_duration_seconds_count{outcome="success",provider="Azure/azapi"} 1`)
+	assert.Contains(t, body, "terraform_mcp_eva_active_sessions 0")
+}
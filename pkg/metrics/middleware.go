@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"time"
+
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/toolcall"
+	"github.com/matt-FFFFFF/tfpluginschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Error classes of the tool_errors_total metric.
+const (
+	ErrorClassInvalidArgument = "invalid_argument"
+	ErrorClassNotFound        = "not_found"
+	ErrorClassUpstream        = "upstream"
+	ErrorClassTimeout         = "timeout"
+	ErrorClassCanceled        = "canceled"
+	// ErrorClassToolResult is a result flagged as an error by the tool rather than a returned error.
+	ErrorClassToolResult = "tool_result"
+	ErrorClassInternal   = "internal"
+)
+
+// ToolMiddleware records the count, duration and errors of every tool call.
+func ToolMiddleware(next toolcall.Handler) toolcall.Handler {
+	return func(ctx context.Context, call *toolcall.Call) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		res, err := next(ctx, call)
+		toolDuration.WithLabelValues(call.Name).Observe(time.Since(start).Seconds())
+		failed := err != nil || (res != nil && res.IsError)
+		toolCalls.WithLabelValues(call.Name, outcome(!failed)).Inc()
+		if failed {
+			toolErrors.WithLabelValues(call.Name, ErrorClass(err)).Inc()
+		}
+		return res, err
+	}
+}
+
+// ErrorClass maps a tool error to a coarse class suitable as a metric label.
+func ErrorClass(err error) string {
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return ErrorClassToolResult
+	case errors.Is(err, context.Canceled):
+		return ErrorClassCanceled
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded):
+		return ErrorClassTimeout
+	case errors.Is(err, toolcall.ErrInvalidArgument):
+		return ErrorClassInvalidArgument
+	case errors.Is(err, tfpluginschema.ErrPluginNotFound):
+		return ErrorClassNotFound
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorClassTimeout
+	case netErr != nil, errors.Is(err, tfpluginschema.ErrPluginApi):
+		return ErrorClassUpstream
+	default:
+		return ErrorClassInternal
+	}
+}
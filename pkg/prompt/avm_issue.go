@@ -7,6 +7,9 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+// SolveAvmIssue is the name of the prompt added by AddSolveAvmIssuePrompt.
+const SolveAvmIssue = "solve_avm_issue"
+
 func AddSolveAvmIssuePrompt(s *mcp.Server) {
 	s.AddPrompt(&mcp.Prompt{
 		Arguments: []*mcp.PromptArgument{
@@ -20,7 +23,7 @@ func AddSolveAvmIssuePrompt(s *mcp.Server) {
 			},
 		},
 		Description: "If you're processing changes to repo that contains `terraform` and `avm` in repo's name, and there is `avm` or `avm.bat` file in the root directory, you should use this prompt to get instructions on how to process the changes. The prompt will return a list of instructions that you can follow to process the changes.",
-		Name:        "solve_avm_issue",
+		Name:        SolveAvmIssue,
 	}, func(ctx context.Context, session *mcp.ServerSession, params *mcp.GetPromptParams) (*mcp.GetPromptResult, error) {
 		issueNumber := params.Arguments["issue_number"]
 		category := params.Arguments["category"]
@@ -1,46 +1,141 @@
 package pkg
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
 	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/prompt"
 	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/tool"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/toolcall"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-func RegisterMcpServer(s *mcp.Server) {
-	mcp.AddTool(s, &mcp.Tool{
+// Tool groups.
+const (
+	GroupAzAPI             = "azapi"
+	GroupTerraformProvider = "terraform-provider"
+)
+
+// Options selects what RegisterMcpServer adds to the server.
+type Options struct {
+	// Groups lists the tool groups to add, e.g. GroupAzAPI.
+	Groups []string
+	// Tools lists tools to add in addition to those of Groups. All tools are added when both are empty.
+	Tools []string
+	// DisabledTools are left out even when their group is enabled.
+	DisabledTools []string
+	// Prompts lists the prompts to add, all prompts are added when it's empty.
+	Prompts []string
+	// DisabledPrompts are left out even when listed in Prompts.
+	DisabledPrompts []string
+}
+
+// RegisterMcpServer adds the selected tools and prompts to s, wrapping every tool handler with middlewares.
+// Unknown groups, tools or prompts are reported as an error, after everything else has been added.
+func RegisterMcpServer(s *mcp.Server, opts Options, middlewares ...toolcall.Middleware) error {
+	r := &registrar{s: s, opts: opts, middlewares: middlewares}
+	addTool(r, GroupAzAPI, &mcp.Tool{
 		Annotations: &mcp.ToolAnnotations{
 			DestructiveHint: p(false),
 			IdempotentHint:  true,
 			OpenWorldHint:   p(false),
 			ReadOnlyHint:    true,
 		},
-		Description: "Query fine grained AzAPI resource body schema by `resource type`, `api_version` and optional `path`. The returned type is a Go type string, which can be used in Go code to represent the resource's `body` attribute. If you're querying corresponds to the AzAPI provider and the `body` attribute, this tool should have higher priority",
+		Description: "Query fine grained AzAPI resource body schema by `resource type`, `api_version` and optional `path`. The returned type is a Go type string, which can be used in Go code to represent the resource's `body` attribute. Large resources can be explored top-down with `depth`, which returns an outline of property names, types and flags to that many levels. Polymorphic objects are shown with their discriminator values, query a variant with a path like `body.properties[type=AzureBlobStorage]`. Set `response` to query the GET response, the `output` attribute, with its ReadOnly properties and suggested `response_export_values`. Set `block_type` to query the attributes of `azapi_update_resource`, `azapi_data_plane_resource`, `data.azapi_resource` or `azapi_resource_action` (with `action`) instead of `azapi_resource`. Set `azapi_provider_version` to the AzAPI provider version the module uses to get the attributes of that version. If you're querying corresponds to the AzAPI provider and the `body` attribute, this tool should have higher priority",
 		Name:        "query_azapi_resource_body",
 	}, tool.QueryAzAPIResourceSchema)
 
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(r, GroupAzAPI, &mcp.Tool{
 		Annotations: &mcp.ToolAnnotations{
 			DestructiveHint: p(false),
 			IdempotentHint:  true,
 			OpenWorldHint:   p(false),
 			ReadOnlyHint:    true,
 		},
-		Description: "Query Azure API versions by `resource type`, e.g. `Microsoft.Compute/virtualMachines`. The returned value is a list of API versions for the specified resource type, split by comma.",
+		Description: "Query Azure API versions by `resource type`, e.g. `Microsoft.Compute/virtualMachines`. The returned value is a list of API versions for the specified resource type, split by comma. Long lists are paged, pass the returned `cursor` to get the next page.",
 		Name:        "list_azapi_api_versions",
 	}, tool.QueryAzAPIVersions)
 
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(r, GroupAzAPI, &mcp.Tool{
 		Annotations: &mcp.ToolAnnotations{
 			DestructiveHint: p(false),
 			IdempotentHint:  true,
 			OpenWorldHint:   p(false),
 			ReadOnlyHint:    true,
 		},
-		Description: "Query fine grained AzAPI resource description by `resource type`, `api_version` and optional `path`. The returned value is either description of the property, or json object representing the object, the key is property name the value is the description of the property. Via description you can learn whether a property is id, readonly or writeonly, and possible values. If you're querying AzAPI provider and the `body` attribute, this tool should have higher priority",
+		Description: "Query fine grained AzAPI resource description by `resource type`, `api_version` and optional `path`. The returned value is either description of the property, or json object representing the object, the key is property name the value is the description of the property. Via description you can learn whether a property is id, readonly, writeonly or sensitive, its possible values and the constraints Azure validates, e.g. min/max length, pattern, min/max value and min/max items. With `depth` the result is an outline of property names, types, flags and descriptions to that many levels. Polymorphic objects describe their discriminator values, query a variant with a path like `body.properties[type=AzureBlobStorage]`. Set `response` to describe the GET response, the `output` attribute, and get suggested `response_export_values`. Set `block_type` to describe `azapi_update_resource`, `azapi_data_plane_resource`, `data.azapi_resource` or `azapi_resource_action` (with `action`) instead of `azapi_resource`. Set `azapi_provider_version` to the AzAPI provider version the module uses to get the attributes of that version. If you're querying AzAPI provider and the `body` attribute, this tool should have higher priority",
 		Name:        "query_azapi_resource_document",
 	}, tool.QueryAzAPIDescriptionSchema)
 
-	mcp.AddTool(s, &mcp.Tool{
+	addTool(r, GroupAzAPI, &mcp.Tool{
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: p(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   p(false),
+			ReadOnlyHint:    true,
+		},
+		Description: "Query where an AzAPI resource is deployed by `resource_type` and `api_version`, use it to get `parent_id` right. The returned JSON has the `type` attribute value, the parent resource type of child resources, and for every scope the resource can be deployed at (tenant, management_group, subscription, resource_group, extension or any) the format of `parent_id` and of the resource ID. It also has the fixed value or the constraints of `name`, and whether `location` is required, optional or not supported.",
+		Name:        "query_azapi_resource_scope",
+	}, tool.QueryAzAPIResourceScope)
+
+	addTool(r, GroupAzAPI, &mcp.Tool{
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: p(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   p(false),
+			ReadOnlyHint:    true,
+		},
+		Description: "Query the actions of an AzAPI resource, the POST operations called with `azapi_resource_action`, e.g. `listKeys`, `restart` or `regenerateKey`, by `resource_type` and `api_version`. Without `action` the returned value is the list of action names. With `action` the returned JSON has the `type`, `action` and `method` attribute values, and the descriptions of the request `body` and of the response, like query_azapi_resource_document describes the resource body. Query a nested property with a `path` like `response.keys`.",
+		Name:        "query_azapi_resource_action",
+	}, tool.QueryAzAPIResourceAction)
+
+	addTool(r, GroupAzAPI, &mcp.Tool{
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: p(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   p(false),
+			ReadOnlyHint:    true,
+		},
+		Description: "Query how to list AzAPI resources with `azapi_resource_list` by `resource_type` and `api_version`. The returned JSON has the `type` attribute value, the formats of `parent_id` the resources are listed under, and the shape of the list response: `value` is a list of the resource body, described like query_azapi_resource_document describes it, ReadOnly properties included. Actions named `list...`, e.g. `listKeys`, are POST actions, query them with query_azapi_resource_action.",
+		Name:        "query_azapi_resource_list",
+	}, tool.QueryAzAPIResourceList)
+
+	addTool(r, GroupAzAPI, &mcp.Tool{
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: p(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   p(false),
+			ReadOnlyHint:    true,
+		},
+		Description: "Check what breaks when an existing `azapi_resource` block moves to another api-version. Supply the block as `hcl` and the target `api_version`. The returned JSON lists the body properties that become `invalid` (removed, read-only, or with a value no longer allowed), the `removed_values` of the enums the body sets, and the `required` properties the body doesn't set yet; it's followed by the block rewritten for the target api-version, with invalid properties removed and required ones added as TODOs. Only literal values are checked, references such as `var.x` are kept as they are.",
+		Name:        "query_azapi_api_version_upgrade",
+	}, tool.QueryAzAPIVersionUpgrade)
+
+	addTool(r, GroupAzAPI, &mcp.Tool{
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: p(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   p(false),
+			ReadOnlyHint:    true,
+		},
+		Description: "Find the AzAPI resource types, at their latest stable api-version, whose body has a property, e.g. which `Microsoft.Network/` types have `properties.privateEndpointNetworkPolicies`, or which resources support `identity.type` with the value `UserAssigned`. Narrow the search with a `resource_type`, or a prefix ending with `/` such as `Microsoft.Network/`, and match the property by `path`, a possible `value` and words of its `description`. The returned JSON lists the matching `type` and `path`, which query_azapi_resource_document accepts, with the possible values and whether the property is read-only.",
+		Name:        "find_azapi_properties",
+	}, tool.FindAzAPIProperties)
+
+	addTool(r, GroupAzAPI, &mcp.Tool{
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: p(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   p(false),
+			ReadOnlyHint:    true,
+		},
+		Description: "Search the AzAPI resource body properties by words when you don't know their path, e.g. `zone redundant` on `Microsoft.Storage/storageAccounts`. The descriptions, names and possible values of the properties are searched, at the latest stable api-version of each resource type. Narrow the search with a `resource_type`, or a prefix ending with `/` such as `Microsoft.Storage/`; omit it to search all resource types. The returned JSON lists the matching `type` and `path`, most relevant first, with a snippet of the description; query the path with query_azapi_resource_document for details.",
+		Name:        "search_azapi_properties",
+	}, tool.SearchAzAPIProperties)
+
+	addTool(r, GroupTerraformProvider, &mcp.Tool{
 		Annotations: &mcp.ToolAnnotations{
 			DestructiveHint: p(false),
 			IdempotentHint:  true,
@@ -48,10 +143,80 @@ func RegisterMcpServer(s *mcp.Server) {
 			ReadOnlyHint:    true,
 			Title:           "Query Terraform Provider Schema",
 		},
-		Description: "Query Terraform provider schemas by name. Supports resource, ephemeral and data blocks. MUST supply provider name, e.g. azurerm, provider version, e.g. 2.5.0, and the first block label. MUST get provider version from `terraform providers`, The returned value is a JSON string representing the resource schema, including attribute descriptions. If you're querying schema information about specified attribute or nested block schema, this tool should have higher priority.",
+		Description: "Query Terraform provider schemas by name. Supports resource, ephemeral and data blocks. MUST supply provider name, e.g. azurerm, and the first block label. Supply either `working_dir` (or the `lock_file` content) so the provider namespace and version are resolved from `.terraform.lock.hcl` and `required_providers`, or the provider namespace and version explicitly. The version can be exact, e.g. 2.5.0, a constraint, e.g. `~> 4.0`, or `latest`; the concrete version used is reported in the result. The returned value is a JSON string representing the resource schema, including attribute descriptions. If you're querying schema information about specified attribute or nested block schema, this tool should have higher priority.",
 		Name:        "query_terraform_provider_schema",
 	}, tool.QueryResourceSchema)
-	prompt.AddSolveAvmIssuePrompt(s)
+	r.addPrompt(prompt.SolveAvmIssue, prompt.AddSolveAvmIssuePrompt)
+	return r.check()
+}
+
+type registrar struct {
+	s           *mcp.Server
+	opts        Options
+	middlewares []toolcall.Middleware
+	groups      []string
+	tools       []string
+	prompts     []string
+}
+
+func addTool[In, Out any](r *registrar, group string, t *mcp.Tool, h mcp.ToolHandlerFor[In, Out]) {
+	if !slices.Contains(r.groups, group) {
+		r.groups = append(r.groups, group)
+	}
+	r.tools = append(r.tools, t.Name)
+	if !r.opts.ToolEnabled(group, t.Name) {
+		return
+	}
+	mcp.AddTool(r.s, t, toolcall.Wrap(t.Name, h, r.middlewares...))
+}
+
+// ToolEnabled tells whether the tool name of group is added.
+func (o Options) ToolEnabled(group, name string) bool {
+	if slices.Contains(o.DisabledTools, name) {
+		return false
+	}
+	if len(o.Groups) == 0 && len(o.Tools) == 0 {
+		return true
+	}
+	return slices.Contains(o.Groups, group) || slices.Contains(o.Tools, name)
+}
+
+func (r *registrar) addPrompt(name string, add func(*mcp.Server)) {
+	r.prompts = append(r.prompts, name)
+	if slices.Contains(r.opts.DisabledPrompts, name) {
+		return
+	}
+	if len(r.opts.Prompts) > 0 && !slices.Contains(r.opts.Prompts, name) {
+		return
+	}
+	add(r.s)
+}
+
+// check rejects names that don't exist, a typo would otherwise silently drop a tool or keep one enabled.
+func (r *registrar) check() error {
+	var errs []error
+	for _, c := range []struct {
+		kind      string
+		names     []string
+		available []string
+	}{
+		{"tool groups", r.opts.Groups, r.groups},
+		{"tools", r.opts.Tools, r.tools},
+		{"tools", r.opts.DisabledTools, r.tools},
+		{"prompts", r.opts.Prompts, r.prompts},
+		{"prompts", r.opts.DisabledPrompts, r.prompts},
+	} {
+		var unknown []string
+		for _, name := range c.names {
+			if !slices.Contains(c.available, name) {
+				unknown = append(unknown, name)
+			}
+		}
+		if len(unknown) > 0 {
+			errs = append(errs, fmt.Errorf("unknown %s %s, available %s are %s", c.kind, strings.Join(unknown, ", "), c.kind, strings.Join(c.available, ", ")))
+		}
+	}
+	return errors.Join(errs...)
 }
 
 func p[T any](input T) *T {
@@ -0,0 +1,71 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/prompt"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listed registers the server with opts and returns the tools and prompts a client sees.
+func listed(t *testing.T, opts Options) ([]string, []string, error) {
+	t.Helper()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	regErr := RegisterMcpServer(server, opts)
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	ss, err := server.Connect(ctx, serverTransport)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ss.Close() })
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "client"}, nil).Connect(ctx, clientTransport)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cs.Close() })
+
+	var tools, prompts []string
+	toolsResult, err := cs.ListTools(ctx, nil)
+	require.NoError(t, err)
+	for _, tool := range toolsResult.Tools {
+		tools = append(tools, tool.Name)
+	}
+	promptsResult, err := cs.ListPrompts(ctx, nil)
+	require.NoError(t, err)
+	for _, p := range promptsResult.Prompts {
+		prompts = append(prompts, p.Name)
+	}
+	return tools, prompts, regErr
+}
+
+func TestRegisterMcpServer_Everything(t *testing.T) {
+	tools, prompts, err := listed(t, Options{})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"query_azapi_resource_body", "list_azapi_api_versions", "query_azapi_resource_document", "query_azapi_resource_scope", "query_azapi_resource_action", "query_azapi_resource_list", "query_azapi_api_version_upgrade", "find_azapi_properties", "search_azapi_properties", "query_terraform_provider_schema"}, tools)
+	assert.Equal(t, []string{prompt.SolveAvmIssue}, prompts)
+}
+
+func TestRegisterMcpServer_GroupsAndTools(t *testing.T) {
+	tools, prompts, err := listed(t, Options{
+		Groups:          []string{GroupAzAPI},
+		Tools:           []string{"query_terraform_provider_schema"},
+		DisabledTools:   []string{"query_azapi_resource_document"},
+		DisabledPrompts: []string{prompt.SolveAvmIssue},
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"query_azapi_resource_body", "list_azapi_api_versions", "query_azapi_resource_scope", "query_azapi_resource_action", "query_azapi_resource_list", "query_azapi_api_version_upgrade", "find_azapi_properties", "search_azapi_properties", "query_terraform_provider_schema"}, tools)
+	assert.Empty(t, prompts)
+}
+
+func TestRegisterMcpServer_UnknownNames(t *testing.T) {
+	tools, _, err := listed(t, Options{
+		Groups:  []string{GroupTerraformProvider, "formatting"},
+		Tools:   []string{"query_azapi_resource_bdy"},
+		Prompts: []string{"nope"},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown tool groups formatting, available tool groups are azapi, terraform-provider")
+	assert.Contains(t, err.Error(), "unknown tools query_azapi_resource_bdy")
+	assert.Contains(t, err.Error(), "unknown prompts nope")
+	assert.Equal(t, []string{"query_terraform_provider_schema"}, tools)
+}
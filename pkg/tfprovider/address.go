@@ -0,0 +1,51 @@
+package tfprovider
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// DefaultHostname is the registry hostname implied by a provider source address without one.
+	DefaultHostname = "registry.terraform.io"
+	// DefaultNamespace is the namespace Terraform assumes for providers declared without a source address.
+	DefaultNamespace = "hashicorp"
+)
+
+// Address is a fully qualified provider source address, e.g. registry.terraform.io/hashicorp/azurerm.
+type Address struct {
+	Hostname  string
+	Namespace string
+	Type      string
+}
+
+// ParseAddress parses a provider source address as written in `required_providers` or `.terraform.lock.hcl`.
+// Both `namespace/type` and `hostname/namespace/type` forms are accepted; a bare `type` implies the hashicorp namespace.
+func ParseAddress(source string) (Address, error) {
+	parts := strings.Split(strings.TrimSpace(source), "/")
+	for _, part := range parts {
+		if part == "" {
+			return Address{}, fmt.Errorf("invalid provider source address %q", source)
+		}
+	}
+	switch len(parts) {
+	case 1:
+		return Address{Hostname: DefaultHostname, Namespace: DefaultNamespace, Type: strings.ToLower(parts[0])}, nil
+	case 2:
+		return Address{Hostname: DefaultHostname, Namespace: parts[0], Type: strings.ToLower(parts[1])}, nil
+	case 3:
+		return Address{Hostname: strings.ToLower(parts[0]), Namespace: parts[1], Type: strings.ToLower(parts[2])}, nil
+	default:
+		return Address{}, fmt.Errorf("invalid provider source address %q, expected [hostname/]namespace/type", source)
+	}
+}
+
+// String returns the fully qualified form of the address.
+func (a Address) String() string {
+	return fmt.Sprintf("%s/%s/%s", a.Hostname, a.Namespace, a.Type)
+}
+
+// sameProvider reports whether two addresses refer to the same provider, ignoring the case of the namespace.
+func (a Address) sameProvider(b Address) bool {
+	return a.Hostname == b.Hostname && strings.EqualFold(a.Namespace, b.Namespace) && a.Type == b.Type
+}
@@ -0,0 +1,134 @@
+package tfprovider
+
+import (
+	"encoding/json"
+	"fmt"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// pluginSchema is a resource or data source schema as the SchemaServer returns it, the plugin protocol schema in JSON
+// with the attribute types decoded.
+type pluginSchema struct {
+	Version int64       `json:"version"`
+	Block   pluginBlock `json:"block"`
+}
+
+type pluginBlock struct {
+	Attributes  []pluginAttribute   `json:"attributes"`
+	BlockTypes  []pluginNestedBlock `json:"block_types"`
+	Description string              `json:"description"`
+	Deprecated  bool                `json:"deprecated"`
+}
+
+type pluginAttribute struct {
+	Name        string          `json:"name"`
+	Type        json.RawMessage `json:"type"`
+	NestedType  *pluginObject   `json:"nested_type"`
+	Description string          `json:"description"`
+	Required    bool            `json:"required"`
+	Optional    bool            `json:"optional"`
+	Computed    bool            `json:"computed"`
+	Sensitive   bool            `json:"sensitive"`
+	Deprecated  bool            `json:"deprecated"`
+	WriteOnly   bool            `json:"write_only"`
+}
+
+type pluginObject struct {
+	Attributes []pluginAttribute `json:"attributes"`
+	Nesting    int               `json:"nesting"`
+}
+
+type pluginNestedBlock struct {
+	TypeName string      `json:"type_name"`
+	Block    pluginBlock `json:"block"`
+	Nesting  int         `json:"nesting"`
+	MinItems uint64      `json:"min_items"`
+	MaxItems uint64      `json:"max_items"`
+}
+
+// nestingModes are the plugin protocol nesting modes by their enum value.
+var nestingModes = map[int]tfjson.SchemaNestingMode{
+	1: tfjson.SchemaNestingModeSingle,
+	2: tfjson.SchemaNestingModeList,
+	3: tfjson.SchemaNestingModeSet,
+	4: tfjson.SchemaNestingModeMap,
+	5: tfjson.SchemaNestingModeGroup,
+}
+
+// ParseBlockSchema converts a resource or data source schema returned by the SchemaServer to the schema Terraform shows in JSON.
+func ParseBlockSchema(b []byte) (*tfjson.Schema, error) {
+	var schema pluginSchema
+	if err := json.Unmarshal(b, &schema); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal schema: %w", err)
+	}
+	block, err := schema.Block.convert()
+	if err != nil {
+		return nil, err
+	}
+	return &tfjson.Schema{Version: uint64(schema.Version), Block: block}, nil
+}
+
+func (b pluginBlock) convert() (*tfjson.SchemaBlock, error) {
+	block := &tfjson.SchemaBlock{
+		Description: b.Description,
+		Deprecated:  b.Deprecated,
+	}
+	var err error
+	if block.Attributes, err = convertAttributes(b.Attributes); err != nil {
+		return nil, err
+	}
+	if len(b.BlockTypes) > 0 {
+		block.NestedBlocks = make(map[string]*tfjson.SchemaBlockType, len(b.BlockTypes))
+	}
+	for _, nested := range b.BlockTypes {
+		nestedBlock, err := nested.Block.convert()
+		if err != nil {
+			return nil, fmt.Errorf("block %s: %w", nested.TypeName, err)
+		}
+		block.NestedBlocks[nested.TypeName] = &tfjson.SchemaBlockType{
+			NestingMode: nestingModes[nested.Nesting],
+			Block:       nestedBlock,
+			MinItems:    nested.MinItems,
+			MaxItems:    nested.MaxItems,
+		}
+	}
+	return block, nil
+}
+
+func convertAttributes(attributes []pluginAttribute) (map[string]*tfjson.SchemaAttribute, error) {
+	if len(attributes) == 0 {
+		return nil, nil
+	}
+	result := make(map[string]*tfjson.SchemaAttribute, len(attributes))
+	for _, a := range attributes {
+		attribute := &tfjson.SchemaAttribute{
+			Description: a.Description,
+			Required:    a.Required,
+			Optional:    a.Optional,
+			Computed:    a.Computed,
+			Sensitive:   a.Sensitive,
+			Deprecated:  a.Deprecated,
+			WriteOnly:   a.WriteOnly,
+		}
+		if a.NestedType != nil {
+			nested, err := convertAttributes(a.NestedType.Attributes)
+			if err != nil {
+				return nil, fmt.Errorf("attribute %s: %w", a.Name, err)
+			}
+			attribute.AttributeNestedType = &tfjson.SchemaNestedAttributeType{
+				Attributes:  nested,
+				NestingMode: nestingModes[a.NestedType.Nesting],
+			}
+		} else {
+			t, err := ctyjson.UnmarshalType(a.Type)
+			if err != nil {
+				return nil, fmt.Errorf("attribute %s: invalid type: %w", a.Name, err)
+			}
+			attribute.AttributeType = t
+		}
+		result[a.Name] = attribute
+	}
+	return result, nil
+}
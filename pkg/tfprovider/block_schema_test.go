@@ -0,0 +1,50 @@
+package tfprovider
+
+import (
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestParseBlockSchema(t *testing.T) {
+	schema, err := ParseBlockSchema([]byte(`{
+		"version": 2,
+		"block": {
+			"attributes": [
+				{"name": "type", "type": "string", "required": true, "description": "The type of the resource."},
+				{"name": "tags", "type": ["map", "string"], "optional": true, "computed": true},
+				{"name": "body", "type": "dynamic", "optional": true},
+				{"name": "sensitive_body", "type": "dynamic", "optional": true, "write_only": true},
+				{"name": "retry", "nested_type": {"attributes": [{"name": "error_message_regex", "type": ["list", "string"], "required": true}], "nesting": 1}, "optional": true}
+			],
+			"block_types": [
+				{"type_name": "identity", "nesting": 2, "max_items": 1, "block": {"attributes": [{"name": "type", "type": "string", "required": true}]}}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), schema.Version)
+	attributes := schema.Block.Attributes
+	assert.Equal(t, cty.String, attributes["type"].AttributeType)
+	assert.True(t, attributes["type"].Required)
+	assert.Equal(t, "The type of the resource.", attributes["type"].Description)
+	assert.Equal(t, cty.Map(cty.String), attributes["tags"].AttributeType)
+	assert.Equal(t, cty.DynamicPseudoType, attributes["body"].AttributeType)
+	assert.True(t, attributes["sensitive_body"].WriteOnly)
+	require.NotNil(t, attributes["retry"].AttributeNestedType)
+	assert.Equal(t, tfjson.SchemaNestingModeSingle, attributes["retry"].AttributeNestedType.NestingMode)
+	assert.Equal(t, cty.List(cty.String), attributes["retry"].AttributeNestedType.Attributes["error_message_regex"].AttributeType)
+	identity := schema.Block.NestedBlocks["identity"]
+	require.NotNil(t, identity)
+	assert.Equal(t, tfjson.SchemaNestingModeList, identity.NestingMode)
+	assert.Equal(t, uint64(1), identity.MaxItems)
+	assert.True(t, identity.Block.Attributes["type"].Required)
+}
+
+func TestParseBlockSchema_InvalidType(t *testing.T) {
+	_, err := ParseBlockSchema([]byte(`{"block": {"attributes": [{"name": "body", "type": "unknown"}]}}`))
+	assert.ErrorContains(t, err, "attribute body: invalid type")
+}
@@ -0,0 +1,241 @@
+package tfprovider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+)
+
+// CLIConfigFileEnv is the environment variable Terraform reads the CLI configuration file location from.
+const CLIConfigFileEnv = "TF_CLI_CONFIG_FILE"
+
+// CLIConfig is the subset of the Terraform CLI configuration that affects where providers are installed from.
+type CLIConfig struct {
+	// Credentials maps registry hostnames to API tokens.
+	Credentials map[string]string
+	// Services maps hostnames to explicitly configured service URLs, skipping service discovery.
+	Services map[string]map[string]string
+	// Installation lists the provider installation methods in the order they are tried.
+	// It is empty when the configuration has no provider_installation block, which means direct installation only.
+	Installation []InstallationMethod
+}
+
+// InstallationMethod is one of the methods of a provider_installation block.
+type InstallationMethod struct {
+	// Kind is one of direct, filesystem_mirror or network_mirror.
+	Kind string
+	// Location is the directory of a filesystem mirror or the base URL of a network mirror.
+	Location string
+	Include  []string
+	Exclude  []string
+}
+
+// Kinds of InstallationMethod.
+const (
+	InstallationDirect           = "direct"
+	InstallationFilesystemMirror = "filesystem_mirror"
+	InstallationNetworkMirror    = "network_mirror"
+)
+
+type cliConfigFile struct {
+	Credentials  []cliCredentials          `hcl:"credentials,block"`
+	Hosts        []cliHost                 `hcl:"host,block"`
+	Installation []cliProviderInstallation `hcl:"provider_installation,block"`
+	Remain       hcl.Body                  `hcl:",remain"`
+}
+
+type cliCredentials struct {
+	Host  string `hcl:"host,label"`
+	Token string `hcl:"token"`
+}
+
+type cliHost struct {
+	Host     string            `hcl:"host,label"`
+	Services map[string]string `hcl:"services"`
+}
+
+type cliProviderInstallation struct {
+	Body hcl.Body `hcl:",remain"`
+}
+
+type cliDirect struct {
+	Include []string `hcl:"include,optional"`
+	Exclude []string `hcl:"exclude,optional"`
+}
+
+type cliFilesystemMirror struct {
+	Path    string   `hcl:"path"`
+	Include []string `hcl:"include,optional"`
+	Exclude []string `hcl:"exclude,optional"`
+}
+
+type cliNetworkMirror struct {
+	URL     string   `hcl:"url"`
+	Include []string `hcl:"include,optional"`
+	Exclude []string `hcl:"exclude,optional"`
+}
+
+var providerInstallationSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: InstallationDirect},
+		{Type: InstallationFilesystemMirror},
+		{Type: InstallationNetworkMirror},
+	},
+}
+
+// LoadCLIConfig loads the Terraform CLI configuration file named by TF_CLI_CONFIG_FILE,
+// or the default per-user file when the variable isn't set. A missing default file yields an empty configuration.
+func LoadCLIConfig() (*CLIConfig, error) {
+	path, explicit := os.LookupEnv(CLIConfigFileEnv)
+	if !explicit {
+		path = defaultCLIConfigPath()
+	}
+	if path == "" {
+		return &CLIConfig{}, nil
+	}
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) && !explicit {
+		return &CLIConfig{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read terraform cli config %s: %w", path, err)
+	}
+	return ParseCLIConfig(content, path)
+}
+
+func defaultCLIConfigPath() string {
+	if runtime.GOOS == "windows" {
+		if appData := os.Getenv("APPDATA"); appData != "" {
+			return filepath.Join(appData, "terraform.rc")
+		}
+		return ""
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".terraformrc")
+}
+
+// ParseCLIConfig parses the content of a Terraform CLI configuration file, filename is only used in diagnostics.
+func ParseCLIConfig(content []byte, filename string) (*CLIConfig, error) {
+	file, diags := hclparse.NewParser().ParseHCL(content, filename)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse terraform cli config %s: %s", filename, diags.Error())
+	}
+	var raw cliConfigFile
+	if diags = gohcl.DecodeBody(file.Body, nil, &raw); diags.HasErrors() {
+		return nil, fmt.Errorf("failed to decode terraform cli config %s: %s", filename, diags.Error())
+	}
+	config := &CLIConfig{
+		Credentials: make(map[string]string),
+		Services:    make(map[string]map[string]string),
+	}
+	for _, c := range raw.Credentials {
+		config.Credentials[strings.ToLower(c.Host)] = c.Token
+	}
+	for _, h := range raw.Hosts {
+		config.Services[strings.ToLower(h.Host)] = h.Services
+	}
+	if len(raw.Installation) > 1 {
+		return nil, fmt.Errorf("terraform cli config %s: only one provider_installation block is allowed", filename)
+	}
+	for _, pi := range raw.Installation {
+		methods, err := decodeInstallationMethods(pi.Body)
+		if err != nil {
+			return nil, fmt.Errorf("terraform cli config %s: %w", filename, err)
+		}
+		config.Installation = methods
+	}
+	return config, nil
+}
+
+func decodeInstallationMethods(body hcl.Body) ([]InstallationMethod, error) {
+	content, diags := body.Content(providerInstallationSchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	methods := make([]InstallationMethod, 0, len(content.Blocks))
+	for _, block := range content.Blocks {
+		method := InstallationMethod{Kind: block.Type}
+		switch block.Type {
+		case InstallationDirect:
+			var raw cliDirect
+			diags = gohcl.DecodeBody(block.Body, nil, &raw)
+			method.Include, method.Exclude = raw.Include, raw.Exclude
+		case InstallationFilesystemMirror:
+			var raw cliFilesystemMirror
+			diags = gohcl.DecodeBody(block.Body, nil, &raw)
+			method.Location, method.Include, method.Exclude = raw.Path, raw.Include, raw.Exclude
+		case InstallationNetworkMirror:
+			var raw cliNetworkMirror
+			diags = gohcl.DecodeBody(block.Body, nil, &raw)
+			if !strings.HasSuffix(raw.URL, "/") {
+				raw.URL += "/"
+			}
+			method.Location, method.Include, method.Exclude = raw.URL, raw.Include, raw.Exclude
+		}
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		methods = append(methods, method)
+	}
+	return methods, nil
+}
+
+// token returns the API token for a hostname, from a credentials block or a TF_TOKEN_ environment variable.
+func (c *CLIConfig) token(hostname string) string {
+	hostname = strings.ToLower(hostname)
+	if c != nil {
+		if t, ok := c.Credentials[hostname]; ok {
+			return t
+		}
+	}
+	envName := "TF_TOKEN_" + strings.ReplaceAll(strings.ReplaceAll(hostname, "-", "__"), ".", "_")
+	return os.Getenv(envName)
+}
+
+// methods returns the installation methods to try for a provider, in order.
+func (c *CLIConfig) methods(addr Address) []InstallationMethod {
+	if c == nil || len(c.Installation) == 0 {
+		return []InstallationMethod{{Kind: InstallationDirect}}
+	}
+	var result []InstallationMethod
+	for _, m := range c.Installation {
+		if m.matches(addr) {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+func (m InstallationMethod) matches(addr Address) bool {
+	if len(m.Include) > 0 && !matchesAnyPattern(addr, m.Include) {
+		return false
+	}
+	return !matchesAnyPattern(addr, m.Exclude)
+}
+
+// matchesAnyPattern matches an address against provider address patterns such as `registry.terraform.io/hashicorp/*`.
+func matchesAnyPattern(addr Address, patterns []string) bool {
+	for _, p := range patterns {
+		pattern, err := ParseAddress(p)
+		if err != nil {
+			continue
+		}
+		if matchSegment(pattern.Hostname, addr.Hostname) && matchSegment(pattern.Namespace, addr.Namespace) && matchSegment(pattern.Type, addr.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegment(pattern, value string) bool {
+	return pattern == "*" || strings.EqualFold(pattern, value)
+}
@@ -0,0 +1,95 @@
+package tfprovider
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCLIConfig = `
+plugin_cache_dir = "$HOME/.terraform.d/plugin-cache"
+
+credentials "registry.example.com" {
+  token = "s3cr3t"
+}
+
+host "registry.example.com" {
+  services = {
+    "providers.v1" = "https://registry.example.com/api/providers/"
+  }
+}
+
+provider_installation {
+  filesystem_mirror {
+    path    = "/usr/share/terraform/providers"
+    include = ["registry.example.com/*/*"]
+  }
+  network_mirror {
+    url     = "https://mirror.example.com/providers"
+    exclude = ["registry.example.com/*/*"]
+  }
+  direct {
+    exclude = ["hashicorp/*"]
+  }
+}
+`
+
+func TestParseCLIConfig(t *testing.T) {
+	config, err := ParseCLIConfig([]byte(testCLIConfig), ".terraformrc")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"registry.example.com": "s3cr3t"}, config.Credentials)
+	assert.Equal(t, "https://registry.example.com/api/providers/", config.Services["registry.example.com"][providersServiceID])
+	assert.Equal(t, []InstallationMethod{
+		{Kind: InstallationFilesystemMirror, Location: "/usr/share/terraform/providers", Include: []string{"registry.example.com/*/*"}},
+		{Kind: InstallationNetworkMirror, Location: "https://mirror.example.com/providers/", Exclude: []string{"registry.example.com/*/*"}},
+		{Kind: InstallationDirect, Exclude: []string{"hashicorp/*"}},
+	}, config.Installation)
+}
+
+func TestCLIConfig_Methods(t *testing.T) {
+	config, err := ParseCLIConfig([]byte(testCLIConfig), ".terraformrc")
+	require.NoError(t, err)
+
+	kinds := func(addr string) []string {
+		a, err := ParseAddress(addr)
+		require.NoError(t, err)
+		var result []string
+		for _, m := range config.methods(a) {
+			result = append(result, m.Kind)
+		}
+		return result
+	}
+	assert.Equal(t, []string{InstallationFilesystemMirror, InstallationDirect}, kinds("registry.example.com/acme/internal"))
+	assert.Equal(t, []string{InstallationNetworkMirror}, kinds("hashicorp/azurerm"))
+	assert.Equal(t, []string{InstallationNetworkMirror, InstallationDirect}, kinds("Azure/azapi"))
+}
+
+func TestCLIConfig_NilMeansDirect(t *testing.T) {
+	var config *CLIConfig
+	assert.Equal(t, []InstallationMethod{{Kind: InstallationDirect}}, config.methods(Address{Hostname: DefaultHostname, Namespace: "Azure", Type: "azapi"}))
+}
+
+func TestCLIConfig_TokenFromEnvironment(t *testing.T) {
+	t.Setenv("TF_TOKEN_my-registry_example_com", "")
+	t.Setenv("TF_TOKEN_my__registry_example_com", "from-env")
+	config := &CLIConfig{}
+	assert.Equal(t, "from-env", config.token("my-registry.example.com"))
+}
+
+func TestLoadCLIConfig_FromEnvironment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "custom.tfrc")
+	require.NoError(t, os.WriteFile(path, []byte(testCLIConfig), 0600))
+	t.Setenv(CLIConfigFileEnv, path)
+	config, err := LoadCLIConfig()
+	require.NoError(t, err)
+	assert.Len(t, config.Installation, 3)
+}
+
+func TestLoadCLIConfig_MissingExplicitFile(t *testing.T) {
+	t.Setenv(CLIConfigFileEnv, filepath.Join(t.TempDir(), "missing.tfrc"))
+	_, err := LoadCLIConfig()
+	require.Error(t, err)
+}
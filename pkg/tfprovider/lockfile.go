@@ -0,0 +1,75 @@
+package tfprovider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+)
+
+// LockFileName is the name of the dependency lock file Terraform writes into the working directory.
+const LockFileName = ".terraform.lock.hcl"
+
+// LockedProvider is a provider selection recorded in a dependency lock file.
+type LockedProvider struct {
+	Address     Address
+	Version     string
+	Constraints string
+}
+
+type lockFile struct {
+	Providers []lockFileProvider `hcl:"provider,block"`
+	Remain    hcl.Body           `hcl:",remain"`
+}
+
+type lockFileProvider struct {
+	Source      string   `hcl:"source,label"`
+	Version     string   `hcl:"version"`
+	Constraints *string  `hcl:"constraints,optional"`
+	Hashes      []string `hcl:"hashes,optional"`
+}
+
+// ReadLockFile reads the dependency lock file in the given Terraform working directory.
+// A missing lock file is not an error, it yields no locked providers.
+func ReadLockFile(dir string) ([]LockedProvider, error) {
+	path := filepath.Join(dir, LockFileName)
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lock file %s: %w", path, err)
+	}
+	return ParseLockFile(content, path)
+}
+
+// ParseLockFile parses the content of a dependency lock file, filename is only used in diagnostics.
+func ParseLockFile(content []byte, filename string) ([]LockedProvider, error) {
+	file, diags := hclparse.NewParser().ParseHCL(content, filename)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse lock file %s: %s", filename, diags.Error())
+	}
+	var lf lockFile
+	if diags = gohcl.DecodeBody(file.Body, nil, &lf); diags.HasErrors() {
+		return nil, fmt.Errorf("failed to decode lock file %s: %s", filename, diags.Error())
+	}
+	result := make([]LockedProvider, 0, len(lf.Providers))
+	for _, p := range lf.Providers {
+		addr, err := ParseAddress(p.Source)
+		if err != nil {
+			return nil, fmt.Errorf("invalid provider in lock file %s: %w", filename, err)
+		}
+		locked := LockedProvider{
+			Address: addr,
+			Version: p.Version,
+		}
+		if p.Constraints != nil {
+			locked.Constraints = *p.Constraints
+		}
+		result = append(result, locked)
+	}
+	return result, nil
+}
@@ -0,0 +1,61 @@
+package tfprovider
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testLockFile = `# This file is maintained automatically by "terraform init".
+# Manual edits may be lost in future updates.
+
+provider "registry.terraform.io/azure/azapi" {
+  version     = "2.5.0"
+  constraints = ">= 2.0.0, < 3.0.0"
+  hashes = [
+    "h1:abc=",
+  ]
+}
+
+provider "registry.terraform.io/hashicorp/azurerm" {
+  version = "4.37.0"
+  hashes = [
+    "h1:def=",
+  ]
+}
+`
+
+func TestParseLockFile(t *testing.T) {
+	locked, err := ParseLockFile([]byte(testLockFile), LockFileName)
+	require.NoError(t, err)
+	require.Len(t, locked, 2)
+	assert.Equal(t, LockedProvider{
+		Address:     Address{Hostname: "registry.terraform.io", Namespace: "azure", Type: "azapi"},
+		Version:     "2.5.0",
+		Constraints: ">= 2.0.0, < 3.0.0",
+	}, locked[0])
+	assert.Equal(t, "4.37.0", locked[1].Version)
+	assert.Empty(t, locked[1].Constraints)
+}
+
+func TestReadLockFile_Missing(t *testing.T) {
+	locked, err := ReadLockFile(t.TempDir())
+	require.NoError(t, err)
+	assert.Empty(t, locked)
+}
+
+func TestReadLockFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, LockFileName), []byte(testLockFile), 0600))
+	locked, err := ReadLockFile(dir)
+	require.NoError(t, err)
+	assert.Len(t, locked, 2)
+}
+
+func TestParseLockFile_Invalid(t *testing.T) {
+	_, err := ParseLockFile([]byte(`provider "azurerm" {`), LockFileName)
+	require.Error(t, err)
+}
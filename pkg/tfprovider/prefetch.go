@@ -0,0 +1,44 @@
+package tfprovider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ParsePrefetchList parses a comma separated list of `[hostname/]namespace/type[@version]` provider references.
+// The version may be an exact version, a constraint or `latest`, the latest version is used when it's omitted.
+func ParsePrefetchList(list string) ([]ResolveRequest, error) {
+	var result []ResolveRequest
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		source, version, _ := strings.Cut(item, "@")
+		addr, err := ParseAddress(source)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, ResolveRequest{
+			LocalName: addr.Type,
+			Source:    addr.String(),
+			Version:   strings.TrimSpace(version),
+		})
+	}
+	return result, nil
+}
+
+// Prefetch resolves the requested providers and downloads them into the schema server, so later schema queries are served from its cache.
+func Prefetch(ctx context.Context, registry *Registry, server *SchemaServer, requests []ResolveRequest) error {
+	for _, req := range requests {
+		selection, err := Resolve(ctx, registry, req)
+		if err != nil {
+			return fmt.Errorf("failed to resolve provider %s: %w", req.Source, err)
+		}
+		if err = server.Get(ctx, *selection); err != nil {
+			return fmt.Errorf("failed to prefetch provider %s version %s: %w", selection.Address, selection.Version, err)
+		}
+	}
+	return nil
+}
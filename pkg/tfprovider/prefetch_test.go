@@ -0,0 +1,21 @@
+package tfprovider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePrefetchList(t *testing.T) {
+	requests, err := ParsePrefetchList("Azure/azapi@2.5.0, hashicorp/azurerm@~> 4.0,example.com/acme/widget,")
+	require.NoError(t, err)
+	assert.Equal(t, []ResolveRequest{
+		{LocalName: "azapi", Source: "registry.terraform.io/Azure/azapi", Version: "2.5.0"},
+		{LocalName: "azurerm", Source: "registry.terraform.io/hashicorp/azurerm", Version: "~> 4.0"},
+		{LocalName: "widget", Source: "example.com/acme/widget"},
+	}, requests)
+
+	_, err = ParsePrefetchList("a/b/c/d")
+	require.Error(t, err)
+}
@@ -0,0 +1,151 @@
+package tfprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// DefaultRegistryURL is the provider registry API used for providers published to the public registry.
+// It is the same registry tfpluginschema downloads provider binaries from.
+const DefaultRegistryURL = "https://registry.opentofu.org/v1/providers"
+
+const providersServiceID = "providers.v1"
+
+// ContextKey is a type used to store the Registry instance in the context.
+type ContextKey struct{}
+
+// Registry lists and locates provider packages, honoring the installation methods,
+// credentials and host overrides of a Terraform CLI configuration.
+type Registry struct {
+	// BaseURL is the providers.v1 endpoint used for the public registry.
+	BaseURL string
+	Client  *http.Client
+	// Config is the Terraform CLI configuration, nil means direct installation from the public registry.
+	Config *CLIConfig
+
+	discoveryMu sync.Mutex
+	discovered  map[string]*url.URL
+}
+
+// NewRegistry creates a Registry that installs providers as described by config, which may be nil.
+func NewRegistry(config *CLIConfig) *Registry {
+	return &Registry{
+		BaseURL: DefaultRegistryURL,
+		// Not http.DefaultClient: its transport may be the one returned by Transport, which calls back into the Registry.
+		Client: &http.Client{},
+		Config: config,
+	}
+}
+
+type versionsResponse struct {
+	Versions []struct {
+		Version string `json:"version"`
+	} `json:"versions"`
+}
+
+// ListVersions returns every version of the provider available from the installation methods that apply to it.
+func (r *Registry) ListVersions(ctx context.Context, addr Address) ([]string, error) {
+	methods := r.Config.methods(addr)
+	if len(methods) == 0 {
+		return nil, fmt.Errorf("no provider installation method applies to %s", addr)
+	}
+	seen := make(map[string]struct{})
+	var result []string
+	var errs []error
+	for _, m := range methods {
+		versions, err := r.source(m).listVersions(ctx, addr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", m.Kind, err))
+			continue
+		}
+		for _, v := range versions {
+			if _, ok := seen[v]; !ok {
+				seen[v] = struct{}{}
+				result = append(result, v)
+			}
+		}
+	}
+	if len(result) == 0 && len(errs) > 0 {
+		return nil, fmt.Errorf("provider %s not available: %w", addr, errors.Join(errs...))
+	}
+	return result, nil
+}
+
+// findPackage locates the provider package from the first installation method that has it.
+func (r *Registry) findPackage(ctx context.Context, addr Address, version string, platform Platform) (*providerPackage, error) {
+	var errs, failures []error
+	for _, m := range r.Config.methods(addr) {
+		pkg, err := r.source(m).findPackage(ctx, addr, version, platform)
+		if err == nil {
+			return pkg, nil
+		}
+		err = fmt.Errorf("%s: %w", m.Kind, err)
+		errs = append(errs, err)
+		if !errors.Is(err, errPackageNotFound) {
+			failures = append(failures, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("%w: no provider installation method applies to %s", errPackageNotFound, addr)
+	}
+	if len(failures) > 0 {
+		// The package may be in a source that failed, so it's only not found when every source said so.
+		return nil, fmt.Errorf("provider %s %s for %s not available: %w", addr, version, platform, errors.Join(failures...))
+	}
+	return nil, fmt.Errorf("provider %s %s for %s not available: %w", addr, version, platform, errors.Join(errs...))
+}
+
+func (r *Registry) source(m InstallationMethod) source {
+	switch m.Kind {
+	case InstallationFilesystemMirror:
+		return filesystemMirror{dir: m.Location}
+	case InstallationNetworkMirror:
+		return networkMirror{r: r, url: m.Location}
+	default:
+		return directSource{r: r}
+	}
+}
+
+// providersURL returns the providers.v1 endpoint of a registry host, from the CLI configuration or service discovery.
+func (r *Registry) providersURL(ctx context.Context, hostname string) (*url.URL, error) {
+	if isPublicRegistryHost(hostname) {
+		return url.Parse(strings.TrimSuffix(r.BaseURL, "/") + "/")
+	}
+	if r.Config != nil {
+		if u, ok := r.Config.Services[hostname][providersServiceID]; ok {
+			return url.Parse(u)
+		}
+	}
+	r.discoveryMu.Lock()
+	defer r.discoveryMu.Unlock()
+	if u, ok := r.discovered[hostname]; ok {
+		return u, nil
+	}
+	discoveryURL := &url.URL{Scheme: "https", Host: hostname, Path: "/.well-known/terraform.json"}
+	var services map[string]any
+	if err := r.getJSON(ctx, discoveryURL, &services); err != nil {
+		return nil, fmt.Errorf("service discovery for %s failed: %w", hostname, err)
+	}
+	raw, ok := services[providersServiceID].(string)
+	if !ok {
+		return nil, fmt.Errorf("host %s does not provide a provider registry", hostname)
+	}
+	u, err := discoveryURL.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s URL %q for host %s: %w", providersServiceID, raw, hostname, err)
+	}
+	if r.discovered == nil {
+		r.discovered = make(map[string]*url.URL)
+	}
+	r.discovered[hostname] = u
+	return u, nil
+}
+
+func isPublicRegistryHost(hostname string) bool {
+	return hostname == DefaultHostname || hostname == "registry.opentofu.org"
+}
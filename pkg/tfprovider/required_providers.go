@@ -0,0 +1,133 @@
+package tfprovider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// RequiredProvider is a provider requirement declared in a `terraform.required_providers` block.
+type RequiredProvider struct {
+	// LocalName is the name the module uses for the provider, e.g. the `azurerm` in `azurerm = { ... }`.
+	LocalName   string
+	Address     Address
+	Constraints []string
+}
+
+var terraformBlockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "terraform"},
+	},
+}
+
+var requiredProvidersBlockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "required_providers"},
+	},
+}
+
+// ReadRequiredProviders collects the provider requirements declared by the `.tf` files of a Terraform working directory.
+// Requirements for the same local name declared in several files are merged, their constraints are all kept.
+func ReadRequiredProviders(dir string) (map[string]*RequiredProvider, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list terraform files in %s: %w", dir, err)
+	}
+	sort.Strings(files)
+	parser := hclparse.NewParser()
+	result := make(map[string]*RequiredProvider)
+	for _, f := range files {
+		content, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", f, err)
+		}
+		file, diags := parser.ParseHCL(content, f)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("failed to parse %s: %s", f, diags.Error())
+		}
+		if err = collectRequiredProviders(file.Body, result); err != nil {
+			return nil, fmt.Errorf("failed to read required_providers in %s: %w", f, err)
+		}
+	}
+	return result, nil
+}
+
+func collectRequiredProviders(body hcl.Body, result map[string]*RequiredProvider) error {
+	content, _, diags := body.PartialContent(terraformBlockSchema)
+	if diags.HasErrors() {
+		return diags
+	}
+	for _, tfBlock := range content.Blocks {
+		tfContent, _, diags := tfBlock.Body.PartialContent(requiredProvidersBlockSchema)
+		if diags.HasErrors() {
+			return diags
+		}
+		for _, rpBlock := range tfContent.Blocks {
+			attrs, diags := rpBlock.Body.JustAttributes()
+			if diags.HasErrors() {
+				return diags
+			}
+			for name, attr := range attrs {
+				req, err := decodeRequiredProvider(name, attr)
+				if err != nil {
+					return err
+				}
+				if existing, ok := result[name]; ok {
+					existing.Constraints = append(existing.Constraints, req.Constraints...)
+					continue
+				}
+				result[name] = req
+			}
+		}
+	}
+	return nil
+}
+
+func decodeRequiredProvider(name string, attr *hcl.Attribute) (*RequiredProvider, error) {
+	req := &RequiredProvider{
+		LocalName: name,
+	}
+	source := name
+	// Object syntax: `azurerm = { source = "hashicorp/azurerm", version = "~> 4.0" }`.
+	// Items are evaluated one by one so that `configuration_aliases` references don't need an evaluation context.
+	if pairs, diags := hcl.ExprMap(attr.Expr); !diags.HasErrors() {
+		for _, pair := range pairs {
+			key := hcl.ExprAsKeyword(pair.Key)
+			if key != "source" && key != "version" {
+				continue
+			}
+			val, diags := pair.Value.Value(nil)
+			if diags.HasErrors() {
+				return nil, fmt.Errorf("invalid %s for provider %s: %s", key, name, diags.Error())
+			}
+			if val.IsNull() || val.Type() != cty.String {
+				return nil, fmt.Errorf("invalid %s for provider %s: expected a string", key, name)
+			}
+			switch key {
+			case "source":
+				source = strings.TrimSpace(val.AsString())
+			case "version":
+				req.Constraints = append(req.Constraints, strings.TrimSpace(val.AsString()))
+			}
+		}
+	} else {
+		// Legacy syntax: `azurerm = "~> 3.0"`
+		val, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() || val.IsNull() || val.Type() != cty.String {
+			return nil, fmt.Errorf("invalid requirement for provider %s: expected an object or a version string", name)
+		}
+		req.Constraints = append(req.Constraints, strings.TrimSpace(val.AsString()))
+	}
+	addr, err := ParseAddress(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid requirement for provider %s: %w", name, err)
+	}
+	req.Address = addr
+	return req, nil
+}
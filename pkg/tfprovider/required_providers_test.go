@@ -0,0 +1,68 @@
+package tfprovider
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+}
+
+func TestReadRequiredProviders(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "terraform.tf", `
+terraform {
+  required_version = ">= 1.9"
+  required_providers {
+    azapi = {
+      source  = "Azure/azapi"
+      version = "~> 2.0"
+    }
+    azurerm = {
+      source                = "hashicorp/azurerm"
+      version               = ">= 3.0"
+      configuration_aliases = [azurerm.alt]
+    }
+    random = "~> 3.5"
+  }
+}
+`)
+	writeFile(t, dir, "versions.tf", `
+terraform {
+  required_providers {
+    azurerm = {
+      source  = "hashicorp/azurerm"
+      version = "< 5.0"
+    }
+  }
+}
+
+resource "azurerm_resource_group" "this" {
+  name     = var.name
+  location = "westeurope"
+}
+`)
+	required, err := ReadRequiredProviders(dir)
+	require.NoError(t, err)
+	require.Len(t, required, 3)
+
+	assert.Equal(t, Address{Hostname: DefaultHostname, Namespace: "Azure", Type: "azapi"}, required["azapi"].Address)
+	assert.Equal(t, []string{"~> 2.0"}, required["azapi"].Constraints)
+	assert.Equal(t, []string{">= 3.0", "< 5.0"}, required["azurerm"].Constraints)
+	assert.Equal(t, Address{Hostname: DefaultHostname, Namespace: DefaultNamespace, Type: "random"}, required["random"].Address)
+	assert.Equal(t, []string{"~> 3.5"}, required["random"].Constraints)
+}
+
+func TestReadRequiredProviders_NoTerraformBlock(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "main.tf", `resource "azurerm_resource_group" "this" {}`)
+	required, err := ReadRequiredProviders(dir)
+	require.NoError(t, err)
+	assert.Empty(t, required)
+}
@@ -0,0 +1,179 @@
+package tfprovider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Values of Selection.SelectedBy.
+const (
+	SelectedByRequest  = "request"
+	SelectedByLockFile = "lock file"
+	SelectedByRegistry = "registry"
+)
+
+// ResolveRequest describes the provider a schema query is about and where to look for its version.
+type ResolveRequest struct {
+	// LocalName is the provider name used in the configuration, e.g. azurerm.
+	LocalName string
+	// Namespace takes precedence over the source address found in the working directory when set.
+	Namespace string
+	// Source is a full provider source address such as example.com/acme/widget, it takes precedence over Namespace.
+	Source string
+	// Version is an exact version, a version constraint such as `~> 4.0`, or `latest`.
+	// An exact version is used as is, a constraint is resolved against the registry.
+	Version string
+	// WorkingDir is a Terraform working directory containing `.tf` files and, optionally, a lock file.
+	WorkingDir string
+	// LockFile is the content of a `.terraform.lock.hcl` file, used when the working directory isn't reachable.
+	LockFile string
+}
+
+// WorkingDirsContextKey is a type used to store the WorkingDirs in the context of tool calls.
+type WorkingDirsContextKey struct{}
+
+// WorkingDirs tells which working directories clients may have the server read, none when it's the zero value.
+type WorkingDirs struct {
+	// Any allows every directory, for the stdio transport and query mode where the client runs the server itself.
+	Any bool
+	// Roots are the directories the working directories must be in otherwise.
+	Roots []string
+}
+
+// Allows tells whether dir may be read, symbolic links are followed so they can't lead out of the roots.
+func (w WorkingDirs) Allows(dir string) bool {
+	if w.Any {
+		return true
+	}
+	path := realPath(dir)
+	for _, root := range w.Roots {
+		if within(realPath(root), path) {
+			return true
+		}
+	}
+	return false
+}
+
+// realPath returns the absolute path of path with its symbolic links resolved, or only cleaned when it doesn't exist.
+func realPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		return real
+	}
+	return path
+}
+
+// Selection is the provider and version a ResolveRequest resolved to.
+type Selection struct {
+	Address Address
+	Version string
+	// SelectedBy tells where the version came from, one of the SelectedByXxx constants.
+	SelectedBy string
+}
+
+// Resolve works out the namespace and version of a provider.
+// An explicit version or constraint wins, then the lock file, then the newest registry version matching the `required_providers` constraints.
+func Resolve(ctx context.Context, registry *Registry, req ResolveRequest) (*Selection, error) {
+	if req.LocalName == "" {
+		return nil, fmt.Errorf("provider name is required")
+	}
+	var addr *Address
+	if req.Source != "" {
+		source, err := ParseAddress(req.Source)
+		if err != nil {
+			return nil, err
+		}
+		addr = &source
+	} else if req.Namespace != "" {
+		addr = &Address{Hostname: DefaultHostname, Namespace: req.Namespace, Type: strings.ToLower(req.LocalName)}
+	}
+	var constraints []string
+	var locked []LockedProvider
+	if req.WorkingDir != "" {
+		// A mistyped directory would read as a module without constraints or lock file, and resolve the latest version.
+		info, err := os.Stat(req.WorkingDir)
+		if err != nil {
+			return nil, fmt.Errorf("invalid working directory: %w", err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("invalid working directory: %s is not a directory", req.WorkingDir)
+		}
+		required, err := ReadRequiredProviders(req.WorkingDir)
+		if err != nil {
+			return nil, err
+		}
+		if rp, ok := required[req.LocalName]; ok {
+			if addr == nil {
+				addr = &rp.Address
+			}
+			constraints = rp.Constraints
+		}
+		if locked, err = ReadLockFile(req.WorkingDir); err != nil {
+			return nil, err
+		}
+	}
+	if req.LockFile != "" {
+		fromContent, err := ParseLockFile([]byte(req.LockFile), LockFileName)
+		if err != nil {
+			return nil, err
+		}
+		locked = append(locked, fromContent...)
+	}
+	if addr == nil {
+		addr = guessAddress(req.LocalName, locked)
+	}
+
+	if req.Version != "" {
+		if exact, ok := exactVersion(req.Version); ok {
+			return &Selection{Address: *addr, Version: exact, SelectedBy: SelectedByRequest}, nil
+		}
+		constraints = nil
+		if !strings.EqualFold(strings.TrimSpace(req.Version), LatestVersion) {
+			constraints = []string{req.Version}
+		}
+	} else {
+		for _, l := range locked {
+			if l.Address.sameProvider(*addr) {
+				return &Selection{Address: l.Address, Version: l.Version, SelectedBy: SelectedByLockFile}, nil
+			}
+		}
+	}
+	if registry == nil {
+		return nil, fmt.Errorf("no locked version for provider %s and no registry to look it up", addr)
+	}
+	versions, err := registry.ListVersions(ctx, *addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions of provider %s: %w", addr, err)
+	}
+	version, err := LatestMatching(versions, constraints...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select a version of provider %s: %w", addr, err)
+	}
+	return &Selection{Address: *addr, Version: version, SelectedBy: SelectedByRegistry}, nil
+}
+
+// guessAddress picks the locked provider with the given type when there is exactly one,
+// otherwise it falls back to the implied hashicorp namespace like Terraform does.
+func guessAddress(localName string, locked []LockedProvider) *Address {
+	var match *Address
+	for i := range locked {
+		if locked[i].Address.Type != strings.ToLower(localName) {
+			continue
+		}
+		if match != nil {
+			match = nil
+			break
+		}
+		match = &locked[i].Address
+	}
+	if match != nil {
+		return match
+	}
+	addr, _ := ParseAddress(localName)
+	return &addr
+}
@@ -0,0 +1,156 @@
+package tfprovider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRegistry(t *testing.T, versions map[string][]string) *Registry {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vs, ok := versions[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"versions":[`)
+		for i, v := range vs {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `{"version":%q}`, v)
+		}
+		fmt.Fprint(w, `]}`)
+	}))
+	t.Cleanup(srv.Close)
+	registry := NewRegistry(nil)
+	registry.BaseURL = srv.URL + "/v1/providers"
+	return registry
+}
+
+func TestResolve_ExplicitVersion(t *testing.T) {
+	s, err := Resolve(context.Background(), nil, ResolveRequest{LocalName: "azapi", Namespace: "Azure", Version: "2.5.0"})
+	require.NoError(t, err)
+	assert.Equal(t, &Selection{
+		Address:    Address{Hostname: DefaultHostname, Namespace: "Azure", Type: "azapi"},
+		Version:    "2.5.0",
+		SelectedBy: SelectedByRequest,
+	}, s)
+}
+
+func TestResolve_FromLockFileInWorkingDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, LockFileName), []byte(testLockFile), 0600))
+	writeFile(t, dir, "terraform.tf", `terraform {
+  required_providers {
+    azapi = {
+      source  = "Azure/azapi"
+      version = "~> 2.0"
+    }
+  }
+}`)
+	s, err := Resolve(context.Background(), nil, ResolveRequest{LocalName: "azapi", WorkingDir: dir})
+	require.NoError(t, err)
+	assert.Equal(t, "azure", s.Address.Namespace)
+	assert.Equal(t, "2.5.0", s.Version)
+	assert.Equal(t, SelectedByLockFile, s.SelectedBy)
+}
+
+func TestResolve_FromLockFileContent(t *testing.T) {
+	s, err := Resolve(context.Background(), nil, ResolveRequest{LocalName: "azurerm", LockFile: testLockFile})
+	require.NoError(t, err)
+	assert.Equal(t, "hashicorp", s.Address.Namespace)
+	assert.Equal(t, "4.37.0", s.Version)
+}
+
+func TestResolve_FallbackToRegistryWithConstraints(t *testing.T) {
+	registry := newTestRegistry(t, map[string][]string{
+		"/v1/providers/Azure/azapi/versions": {"1.15.0", "2.4.0", "2.5.0", "3.0.0"},
+	})
+	dir := t.TempDir()
+	writeFile(t, dir, "terraform.tf", `terraform {
+  required_providers {
+    azapi = {
+      source  = "Azure/azapi"
+      version = "~> 2.0"
+    }
+  }
+}`)
+	s, err := Resolve(context.Background(), registry, ResolveRequest{LocalName: "azapi", WorkingDir: dir})
+	require.NoError(t, err)
+	assert.Equal(t, "2.5.0", s.Version)
+	assert.Equal(t, SelectedByRegistry, s.SelectedBy)
+}
+
+func TestResolve_ImpliedNamespace(t *testing.T) {
+	registry := newTestRegistry(t, map[string][]string{
+		"/v1/providers/hashicorp/random/versions": {"3.6.0", "3.7.2"},
+	})
+	s, err := Resolve(context.Background(), registry, ResolveRequest{LocalName: "random"})
+	require.NoError(t, err)
+	assert.Equal(t, "hashicorp", s.Address.Namespace)
+	assert.Equal(t, "3.7.2", s.Version)
+}
+
+func TestResolve_VersionConstraintInRequest(t *testing.T) {
+	registry := newTestRegistry(t, map[string][]string{
+		"/v1/providers/hashicorp/azurerm/versions": {"3.117.0", "4.36.0", "4.37.0"},
+	})
+	cases := map[string]string{
+		"~> 3.0":   "3.117.0",
+		"latest":   "4.37.0",
+		"< 4.37.0": "4.36.0",
+	}
+	for constraint, expected := range cases {
+		t.Run(constraint, func(t *testing.T) {
+			// The lock file pins 4.37.0, an explicit constraint in the request must not be overridden by it.
+			s, err := Resolve(context.Background(), registry, ResolveRequest{LocalName: "azurerm", Version: constraint, LockFile: testLockFile})
+			require.NoError(t, err)
+			assert.Equal(t, expected, s.Version)
+			assert.Equal(t, SelectedByRegistry, s.SelectedBy)
+		})
+	}
+}
+
+func TestResolve_SourceAddress(t *testing.T) {
+	s, err := Resolve(context.Background(), nil, ResolveRequest{LocalName: "widget", Source: "example.com/acme/widget", Namespace: "ignored", Version: "1.0.0"})
+	require.NoError(t, err)
+	assert.Equal(t, Address{Hostname: "example.com", Namespace: "acme", Type: "widget"}, s.Address)
+}
+
+func TestResolve_InvalidWorkingDir(t *testing.T) {
+	registry := newTestRegistry(t, map[string][]string{"/v1/providers/Azure/azapi/versions": {"2.5.0"}})
+	_, err := Resolve(context.Background(), registry, ResolveRequest{LocalName: "azapi", WorkingDir: filepath.Join(t.TempDir(), "missing")})
+	assert.ErrorContains(t, err, "invalid working directory")
+
+	file := filepath.Join(t.TempDir(), "main.tf")
+	require.NoError(t, os.WriteFile(file, nil, 0600))
+	_, err = Resolve(context.Background(), registry, ResolveRequest{LocalName: "azapi", WorkingDir: file})
+	assert.ErrorContains(t, err, "is not a directory")
+}
+
+func TestWorkingDirs_Allows(t *testing.T) {
+	root := t.TempDir()
+	module := filepath.Join(root, "module")
+	require.NoError(t, os.Mkdir(module, 0o755))
+	outside := t.TempDir()
+	require.NoError(t, os.Symlink(outside, filepath.Join(root, "escape")))
+
+	dirs := WorkingDirs{Roots: []string{root}}
+	assert.True(t, dirs.Allows(root))
+	assert.True(t, dirs.Allows(module))
+	assert.False(t, dirs.Allows(outside))
+	assert.False(t, dirs.Allows(filepath.Join(root, "..")))
+	assert.False(t, dirs.Allows(filepath.Join(root, "escape")))
+
+	assert.False(t, WorkingDirs{}.Allows(module))
+	assert.True(t, WorkingDirs{Any: true}.Allows(outside))
+}
@@ -0,0 +1,113 @@
+package tfprovider
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/metrics"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/tracing"
+	"github.com/matt-FFFFFF/tfpluginschema"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SchemaServerContextKey is a type used to store the SchemaServer instance in the context.
+type SchemaServerContextKey struct{}
+
+// SchemaServer serializes access to a tfpluginschema.Server, which isn't safe for concurrent use,
+// and records downloads and cache hits of provider binaries.
+type SchemaServer struct {
+	mu     sync.Mutex
+	server *tfpluginschema.Server
+	// fetched mirrors the download cache of server, a provider stays cached once Get succeeded.
+	fetched map[tfpluginschema.Request]struct{}
+}
+
+func NewSchemaServer(server *tfpluginschema.Server) *SchemaServer {
+	return &SchemaServer{
+		server:  server,
+		fetched: make(map[tfpluginschema.Request]struct{}),
+	}
+}
+
+// Get downloads the provider of the selection unless it's already cached.
+func (s *SchemaServer) Get(ctx context.Context, selection Selection) (err error) {
+	ctx, span := startSpan(ctx, "tfpluginschema.Get", selection)
+	defer func() { tracing.End(span, err) }()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	req := PluginRequest(selection)
+	_, hit := s.fetched[req]
+	metrics.ObserveCache(metrics.CacheProviderSchema, hit)
+	span.SetAttributes(attribute.Bool("cache.hit", hit))
+	if hit {
+		return nil
+	}
+
+	fetch := &fetchTrace{ctx: ctx}
+	activeFetch.Store(fetch)
+	start := time.Now()
+	err = s.server.Get(req)
+	activeFetch.Store(nil)
+	fetch.endExtract(err)
+	metrics.ObserveProviderDownload(selection.Address.Namespace+"/"+selection.Address.Type, time.Since(start), err)
+	if err != nil {
+		return err
+	}
+	s.fetched[req] = struct{}{}
+	return nil
+}
+
+func (s *SchemaServer) GetResourceSchema(ctx context.Context, selection Selection, resource string) (b []byte, err error) {
+	_, span := startSpan(ctx, "tfpluginschema.GetResourceSchema", selection, attribute.String("block.label", resource))
+	defer func() { tracing.End(span, err) }()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.server.GetResourceSchema(PluginRequest(selection), resource)
+}
+
+func (s *SchemaServer) GetDataSourceSchema(ctx context.Context, selection Selection, dataSource string) (b []byte, err error) {
+	_, span := startSpan(ctx, "tfpluginschema.GetDataSourceSchema", selection, attribute.String("block.label", dataSource))
+	defer func() { tracing.End(span, err) }()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.server.GetDataSourceSchema(PluginRequest(selection), dataSource)
+}
+
+func (s *SchemaServer) GetEphemeralResourceSchema(ctx context.Context, selection Selection, ephemeralResource string) (b []byte, err error) {
+	_, span := startSpan(ctx, "tfpluginschema.GetEphemeralResourceSchema", selection, attribute.String("block.label", ephemeralResource))
+	defer func() { tracing.End(span, err) }()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.server.GetEphemeralResourceSchema(PluginRequest(selection), ephemeralResource)
+}
+
+func (s *SchemaServer) GetFunctionSchema(ctx context.Context, selection Selection, function string) (b []byte, err error) {
+	_, span := startSpan(ctx, "tfpluginschema.GetFunctionSchema", selection, attribute.String("block.label", function))
+	defer func() { tracing.End(span, err) }()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.server.GetFunctionSchema(PluginRequest(selection), function)
+}
+
+func (s *SchemaServer) GetProviderSchema(ctx context.Context, selection Selection) (b []byte, err error) {
+	_, span := startSpan(ctx, "tfpluginschema.GetProviderSchema", selection)
+	defer func() { tracing.End(span, err) }()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.server.GetProviderSchema(PluginRequest(selection))
+}
+
+// Cleanup removes the downloaded providers.
+func (s *SchemaServer) Cleanup() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.server.Cleanup()
+	s.fetched = make(map[tfpluginschema.Request]struct{})
+}
+
+func startSpan(ctx context.Context, name string, selection Selection, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, attribute.String("provider", selection.Address.String()), attribute.String("provider.version", selection.Version))
+	return tracing.Start(ctx, name, attrs...)
+}
@@ -0,0 +1,87 @@
+package tfprovider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/matt-FFFFFF/tfpluginschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSchemaServer_GetCachesDownloads(t *testing.T) {
+	dir := t.TempDir()
+	azapi := Address{Hostname: DefaultHostname, Namespace: "Azure", Type: "azapi"}
+	packedDir := filepath.Join(dir, azapi.Hostname, azapi.Namespace, azapi.Type)
+	require.NoError(t, os.MkdirAll(packedDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(packedDir, packageFilename(azapi, "2.5.0", testPlatform)), fakeProviderZip(t, "azapi", "2.5.0"), 0644))
+	registry := NewRegistry(&CLIConfig{Installation: []InstallationMethod{
+		{Kind: InstallationFilesystemMirror, Location: dir},
+	}})
+	useTransport(t, registry, roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, fmt.Errorf("unexpected request to %s", r.URL)
+	}))
+	server := NewSchemaServer(tfpluginschema.NewServer(nil))
+	t.Cleanup(server.Cleanup)
+
+	requests, err := ParsePrefetchList("Azure/azapi@2.5.0")
+	require.NoError(t, err)
+	require.NoError(t, Prefetch(context.Background(), registry, server, requests))
+
+	// Once fetched, the provider is served from the cache, even concurrently and with the mirror gone.
+	require.NoError(t, os.RemoveAll(dir))
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, server.Get(context.Background(), Selection{Address: azapi, Version: "2.5.0"}))
+		}()
+	}
+	wg.Wait()
+	require.Error(t, server.Get(context.Background(), Selection{Address: azapi, Version: "2.6.0"}))
+}
+
+func TestSchemaServer_GetTracesDownload(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+	})
+
+	dir := t.TempDir()
+	azapi := Address{Hostname: DefaultHostname, Namespace: "Azure", Type: "azapi"}
+	packedDir := filepath.Join(dir, azapi.Hostname, azapi.Namespace, azapi.Type)
+	require.NoError(t, os.MkdirAll(packedDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(packedDir, packageFilename(azapi, "2.5.0", testPlatform)), fakeProviderZip(t, "azapi", "2.5.0"), 0644))
+	registry := NewRegistry(&CLIConfig{Installation: []InstallationMethod{
+		{Kind: InstallationFilesystemMirror, Location: dir},
+	}})
+	useTransport(t, registry, nil)
+	server := NewSchemaServer(tfpluginschema.NewServer(nil))
+	t.Cleanup(server.Cleanup)
+
+	require.NoError(t, server.Get(context.Background(), Selection{Address: azapi, Version: "2.5.0"}))
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, s := range recorder.Ended() {
+		spans[s.Name()] = s
+	}
+	get, ok := spans["tfpluginschema.Get"]
+	require.True(t, ok)
+	for _, name := range []string{"provider.lookup", "provider.download", "provider.extract"} {
+		s, ok := spans[name]
+		require.True(t, ok, name)
+		assert.Equal(t, get.SpanContext().TraceID(), s.SpanContext().TraceID(), name)
+		assert.Equal(t, get.SpanContext().SpanID(), s.Parent().SpanID(), name)
+	}
+}
@@ -0,0 +1,309 @@
+package tfprovider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// errPackageNotFound signals that a source doesn't have the provider, so the next installation method should be tried.
+var errPackageNotFound = errors.New("provider package not found")
+
+// registryError is an unexpected HTTP status answered by a registry or network mirror.
+type registryError struct {
+	url    string
+	status int
+}
+
+func (e *registryError) Error() string {
+	return fmt.Sprintf("registry API error: %s => %d", e.url, e.status)
+}
+
+// Platform is an operating system and architecture pair a provider package is built for.
+type Platform struct {
+	OS   string
+	Arch string
+}
+
+func (p Platform) String() string {
+	return p.OS + "_" + p.Arch
+}
+
+// providerPackage locates a downloadable provider package.
+type providerPackage struct {
+	// URL of the zip archive, or a file URL of either a zip archive or an unpacked package directory.
+	URL      string
+	Filename string
+}
+
+// source is a place providers can be listed and downloaded from, one per kind of installation method.
+type source interface {
+	listVersions(ctx context.Context, addr Address) ([]string, error)
+	findPackage(ctx context.Context, addr Address, version string, platform Platform) (*providerPackage, error)
+}
+
+func packageFilename(addr Address, version string, platform Platform) string {
+	return fmt.Sprintf("terraform-provider-%s_%s_%s.zip", addr.Type, version, platform)
+}
+
+// directSource talks to the registry hosting the provider, discovering the providers.v1 service of private registries.
+type directSource struct {
+	r *Registry
+}
+
+type downloadResponse struct {
+	Protocols   []string `json:"protocols"`
+	OS          string   `json:"os"`
+	Arch        string   `json:"arch"`
+	FileName    string   `json:"filename"`
+	DownloadURL string   `json:"download_url"`
+}
+
+func (s directSource) listVersions(ctx context.Context, addr Address) ([]string, error) {
+	base, err := s.r.providersURL(ctx, addr.Hostname)
+	if err != nil {
+		return nil, err
+	}
+	var versions versionsResponse
+	if err = s.r.getJSON(ctx, base.JoinPath(addr.Namespace, addr.Type, "versions"), &versions); err != nil {
+		return nil, err
+	}
+	result := make([]string, 0, len(versions.Versions))
+	for _, v := range versions.Versions {
+		result = append(result, v.Version)
+	}
+	return result, nil
+}
+
+func (s directSource) findPackage(ctx context.Context, addr Address, version string, platform Platform) (*providerPackage, error) {
+	base, err := s.r.providersURL(ctx, addr.Hostname)
+	if err != nil {
+		return nil, err
+	}
+	u := base.JoinPath(addr.Namespace, addr.Type, version, "download", platform.OS, platform.Arch)
+	var download downloadResponse
+	if err = s.r.getJSON(ctx, u, &download); err != nil {
+		return nil, err
+	}
+	if download.DownloadURL == "" {
+		return nil, fmt.Errorf("download URL is empty for provider %s %s", addr, version)
+	}
+	// download_url may be relative to the URL it was returned from.
+	downloadURL, err := u.Parse(download.DownloadURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid download URL %q for provider %s %s: %w", download.DownloadURL, addr, version, err)
+	}
+	if err = checkHTTPURL(downloadURL); err != nil {
+		return nil, fmt.Errorf("invalid download URL for provider %s %s: %w", addr, version, err)
+	}
+	filename := download.FileName
+	if filename == "" {
+		filename = packageFilename(addr, version, platform)
+	}
+	return &providerPackage{URL: downloadURL.String(), Filename: filename}, nil
+}
+
+// filesystemMirror reads providers from a local directory in either the packed or the unpacked layout:
+//
+//	HOSTNAME/NAMESPACE/TYPE/terraform-provider-TYPE_VERSION_OS_ARCH.zip
+//	HOSTNAME/NAMESPACE/TYPE/VERSION/OS_ARCH/
+type filesystemMirror struct {
+	dir string
+}
+
+func (m filesystemMirror) providerDir(addr Address) string {
+	return filepath.Join(m.dir, addr.Hostname, addr.Namespace, addr.Type)
+}
+
+func (m filesystemMirror) listVersions(_ context.Context, addr Address) ([]string, error) {
+	entries, err := os.ReadDir(m.providerDir(addr))
+	if os.IsNotExist(err) {
+		return nil, errPackageNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read filesystem mirror %s: %w", m.dir, err)
+	}
+	seen := make(map[string]struct{})
+	prefix := "terraform-provider-" + addr.Type + "_"
+	for _, e := range entries {
+		name := e.Name()
+		switch {
+		case e.IsDir():
+			seen[name] = struct{}{}
+		case strings.HasPrefix(name, prefix) && strings.HasSuffix(name, ".zip"):
+			// terraform-provider-TYPE_VERSION_OS_ARCH.zip
+			parts := strings.Split(strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".zip"), "_")
+			if len(parts) == 3 {
+				seen[parts[0]] = struct{}{}
+			}
+		}
+	}
+	result := make([]string, 0, len(seen))
+	for v := range seen {
+		result = append(result, v)
+	}
+	return result, nil
+}
+
+func (m filesystemMirror) findPackage(_ context.Context, addr Address, version string, platform Platform) (*providerPackage, error) {
+	filename := packageFilename(addr, version, platform)
+	packed := filepath.Join(m.providerDir(addr), filename)
+	if info, err := os.Stat(packed); err == nil && !info.IsDir() {
+		return &providerPackage{URL: fileURL(packed), Filename: filename}, nil
+	}
+	unpacked := filepath.Join(m.providerDir(addr), version, platform.String())
+	if info, err := os.Stat(unpacked); err == nil && info.IsDir() {
+		return &providerPackage{URL: fileURL(unpacked), Filename: filename}, nil
+	}
+	return nil, errPackageNotFound
+}
+
+// checkHTTPURL rejects the package URLs of remote sources which aren't HTTP, a `file` URL would read the local disk.
+func checkHTTPURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%s is not an HTTP URL", u.Redacted())
+	}
+	return nil
+}
+
+// inFilesystemMirror tells whether path, which must be clean and absolute, is inside one of the configured filesystem mirrors.
+func (r *Registry) inFilesystemMirror(path string) bool {
+	if r.Config == nil {
+		return false
+	}
+	for _, m := range r.Config.Installation {
+		if m.Kind != InstallationFilesystemMirror {
+			continue
+		}
+		dir, err := filepath.Abs(m.Location)
+		if err != nil {
+			continue
+		}
+		if within(dir, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// within tells whether path is dir or inside it, both must be clean and absolute.
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func fileURL(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// networkMirror implements the provider network mirror protocol.
+type networkMirror struct {
+	r   *Registry
+	url string
+}
+
+type mirrorIndexResponse struct {
+	Versions map[string]json.RawMessage `json:"versions"`
+}
+
+type mirrorVersionResponse struct {
+	Archives map[string]struct {
+		URL string `json:"url"`
+	} `json:"archives"`
+}
+
+func (m networkMirror) providerURL(addr Address) (*url.URL, error) {
+	base, err := url.Parse(m.url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid network mirror URL %q: %w", m.url, err)
+	}
+	return base.JoinPath(addr.Hostname, addr.Namespace, addr.Type), nil
+}
+
+func (m networkMirror) listVersions(ctx context.Context, addr Address) ([]string, error) {
+	u, err := m.providerURL(addr)
+	if err != nil {
+		return nil, err
+	}
+	var index mirrorIndexResponse
+	if err = m.r.getJSON(ctx, u.JoinPath("index.json"), &index); err != nil {
+		return nil, err
+	}
+	result := make([]string, 0, len(index.Versions))
+	for v := range index.Versions {
+		result = append(result, v)
+	}
+	return result, nil
+}
+
+func (m networkMirror) findPackage(ctx context.Context, addr Address, version string, platform Platform) (*providerPackage, error) {
+	u, err := m.providerURL(addr)
+	if err != nil {
+		return nil, err
+	}
+	u = u.JoinPath(version + ".json")
+	var archives mirrorVersionResponse
+	if err = m.r.getJSON(ctx, u, &archives); err != nil {
+		return nil, err
+	}
+	archive, ok := archives.Archives[platform.String()]
+	if !ok || archive.URL == "" {
+		return nil, errPackageNotFound
+	}
+	archiveURL, err := u.Parse(archive.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive URL %q in network mirror: %w", archive.URL, err)
+	}
+	if err = checkHTTPURL(archiveURL); err != nil {
+		return nil, fmt.Errorf("invalid archive URL in network mirror: %w", err)
+	}
+	return &providerPackage{URL: archiveURL.String(), Filename: packageFilename(addr, version, platform)}, nil
+}
+
+// getJSON fetches and decodes a JSON document, attaching credentials for the target host.
+// A 404 response is reported as errPackageNotFound.
+func (r *Registry) getJSON(ctx context.Context, u *url.URL, target any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request for %s: %w", u, err)
+	}
+	r.authorize(req)
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send HTTP request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", errPackageNotFound, u)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &registryError{url: u.String(), status: resp.StatusCode}
+	}
+	if err = json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("failed to decode response from %s: %w", u, err)
+	}
+	return nil
+}
+
+// authorize adds the bearer token configured for the request's host, if any.
+func (r *Registry) authorize(req *http.Request) {
+	if req.Header.Get("Authorization") != "" {
+		return
+	}
+	token := r.Config.token(req.URL.Host)
+	if token == "" {
+		token = r.Config.token(req.URL.Hostname())
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
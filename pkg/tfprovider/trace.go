@@ -0,0 +1,84 @@
+package tfprovider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// fetchTrace links the requests tfpluginschema sends while downloading a provider to the span of the SchemaServer.Get
+// that triggered them, as tfpluginschema creates its requests without a context.
+// SchemaServer serializes downloads, so there is at most one active fetch.
+type fetchTrace struct {
+	ctx     context.Context
+	mu      sync.Mutex
+	extract trace.Span
+}
+
+var activeFetch atomic.Pointer[fetchTrace]
+
+// startExtract starts the span covering what tfpluginschema does once the package is downloaded, i.e. unzipping it.
+func (f *fetchTrace) startExtract() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.extract == nil {
+		_, f.extract = tracing.Start(f.ctx, "provider.extract")
+	}
+}
+
+func (f *fetchTrace) endExtract(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.extract != nil {
+		tracing.End(f.extract, err)
+	}
+}
+
+// fetchContext returns the context of the active fetch for requests sent without a span, i.e. by tfpluginschema.
+func fetchContext(ctx context.Context) (context.Context, *fetchTrace) {
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, nil
+	}
+	if f := activeFetch.Load(); f != nil {
+		return f.ctx, f
+	}
+	return ctx, nil
+}
+
+// downloadBody ends the download span once the package has been read, which is when extraction starts.
+type downloadBody struct {
+	io.ReadCloser
+	once  sync.Once
+	span  trace.Span
+	fetch *fetchTrace
+}
+
+func (b *downloadBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil {
+		b.finish(err)
+	}
+	return n, err
+}
+
+func (b *downloadBody) Close() error {
+	b.finish(nil)
+	return b.ReadCloser.Close()
+}
+
+func (b *downloadBody) finish(err error) {
+	b.once.Do(func() {
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+		tracing.End(b.span, err)
+		if err == nil && b.fetch != nil {
+			b.fetch.startExtract()
+		}
+	})
+}
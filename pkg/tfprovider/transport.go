@@ -0,0 +1,223 @@
+package tfprovider
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/tracing"
+	"github.com/matt-FFFFFF/tfpluginschema"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// tfpluginschema only knows the public registry: it asks
+// https://registry.opentofu.org/v1/providers/NAMESPACE/TYPE/VERSION/download/OS/ARCH for a download URL,
+// then downloads the zip archive found there with http.DefaultClient.
+const (
+	pluginAPIHost = "registry.opentofu.org"
+	pluginAPIPath = "/v1/providers/"
+)
+
+// PluginRequest converts a selection into a tfpluginschema request.
+// Providers from other registries carry their hostname in the namespace, the Transport strips it again.
+func PluginRequest(selection Selection) tfpluginschema.Request {
+	namespace := selection.Address.Namespace
+	if !isPublicRegistryHost(selection.Address.Hostname) {
+		namespace = selection.Address.Hostname + "/" + namespace
+	}
+	return tfpluginschema.Request{
+		Namespace: namespace,
+		Name:      selection.Address.Type,
+		Version:   selection.Version,
+	}
+}
+
+// Transport returns an http.RoundTripper that lets tfpluginschema install providers the way the Registry does.
+// Install it as the transport of http.DefaultClient, which is the client tfpluginschema uses.
+//
+// Download URL lookups sent to the public registry are answered from the Registry's installation methods,
+// and the package URLs given in the answers are downloaded with the credentials configured for their host,
+// or served from disk for filesystem mirrors. Every other request is passed on to base untouched.
+//
+// tfpluginschema offers no way to set its client, so the transport is process-wide: any other user of
+// http.DefaultClient sending a download URL lookup for registry.opentofu.org gets it answered by the Registry.
+func (r *Registry) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{r: r, base: base}
+}
+
+type transport struct {
+	r    *Registry
+	base http.RoundTripper
+	// issued holds the package URLs the transport answered download URL lookups with, only those are downloaded by it.
+	issued sync.Map
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, fetch := fetchContext(req.Context())
+	if addr, version, platform, ok := parsePluginAPIURL(req.URL); ok {
+		ctx, span := tracing.Start(ctx, "provider.lookup", attribute.String("provider", addr.String()), attribute.String("provider.version", version))
+		resp, err := t.serveDownloadInfo(req.WithContext(ctx), addr, version, platform)
+		if err != nil {
+			tracing.End(span, err)
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			tracing.End(span, fmt.Errorf("download info: %s", resp.Status))
+			return resp, nil
+		}
+		tracing.End(span, nil)
+		return resp, nil
+	}
+
+	if !t.isDownload(req) {
+		return t.base.RoundTrip(req)
+	}
+	ctx, span := tracing.Start(ctx, "provider.download", attribute.String("url.full", req.URL.Redacted()))
+	resp, err := t.download(req.WithContext(ctx))
+	if err != nil {
+		tracing.End(span, err)
+		return nil, err
+	}
+	resp.Body = &downloadBody{ReadCloser: resp.Body, span: span, fetch: fetch}
+	return resp, nil
+}
+
+func (t *transport) download(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme == "file" {
+		return t.serveFile(req)
+	}
+	if req.Header.Get("Authorization") == "" && t.r.Config.token(req.URL.Host) != "" {
+		req = req.Clone(req.Context())
+		t.r.authorize(req)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// isDownload tells whether req downloads a package URL the transport issued, or follows a redirect from one.
+func (t *transport) isDownload(req *http.Request) bool {
+	for r := req; ; r = r.Response.Request {
+		if _, ok := t.issued.Load(r.URL.String()); ok {
+			return true
+		}
+		if r.Response == nil || r.Response.Request == nil {
+			return false
+		}
+	}
+}
+
+// parsePluginAPIURL recognizes the download URL lookups tfpluginschema sends.
+func parsePluginAPIURL(u *url.URL) (Address, string, Platform, bool) {
+	if u.Host != pluginAPIHost || !strings.HasPrefix(u.Path, pluginAPIPath) {
+		return Address{}, "", Platform{}, false
+	}
+	segments := strings.Split(strings.TrimPrefix(u.Path, pluginAPIPath), "/")
+	hostname := DefaultHostname
+	if len(segments) == 7 {
+		hostname, segments = strings.ToLower(segments[0]), segments[1:]
+	}
+	if len(segments) != 6 || segments[3] != "download" {
+		return Address{}, "", Platform{}, false
+	}
+	addr := Address{Hostname: hostname, Namespace: segments[0], Type: strings.ToLower(segments[1])}
+	return addr, segments[2], Platform{OS: segments[4], Arch: segments[5]}, true
+}
+
+func (t *transport) serveDownloadInfo(req *http.Request, addr Address, version string, platform Platform) (*http.Response, error) {
+	pkg, err := t.r.findPackage(req.Context(), addr, version, platform)
+	if err != nil {
+		return textResponse(req, lookupStatus(err), err.Error()), nil
+	}
+	t.issued.Store(pkg.URL, struct{}{})
+	body, err := json.Marshal(downloadResponse{
+		Protocols:   []string{},
+		OS:          platform.OS,
+		Arch:        platform.Arch,
+		FileName:    pkg.Filename,
+		DownloadURL: pkg.URL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal download info: %w", err)
+	}
+	resp := textResponse(req, http.StatusOK, string(body))
+	resp.Header.Set("Content-Type", "application/json")
+	return resp, nil
+}
+
+// lookupStatus is the status a failed download URL lookup is answered with.
+// tfpluginschema reports a 404 as a missing provider, so it's only used when no installation method has the package,
+// a registry rejecting the credentials keeps its 401 or 403, and other failures are a 502.
+func lookupStatus(err error) int {
+	var registryErr *registryError
+	switch {
+	case errors.Is(err, errPackageNotFound):
+		return http.StatusNotFound
+	case errors.As(err, &registryErr) && (registryErr.status == http.StatusUnauthorized || registryErr.status == http.StatusForbidden):
+		return registryErr.status
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// serveFile serves a zip archive from a filesystem mirror, zipping unpacked package directories on the fly.
+// Only the paths inside the configured filesystem mirrors are served.
+func (t *transport) serveFile(req *http.Request) (*http.Response, error) {
+	path := filepath.Clean(filepath.FromSlash(req.URL.Path))
+	if !t.r.inFilesystemMirror(path) {
+		return textResponse(req, http.StatusForbidden, fmt.Sprintf("%s is not in a filesystem mirror", path)), nil
+	}
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return textResponse(req, http.StatusNotFound, err.Error()), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		resp := textResponse(req, http.StatusOK, "")
+		resp.Body = f
+		resp.ContentLength = info.Size()
+		return resp, nil
+	}
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	if err = zw.AddFS(os.DirFS(path)); err != nil {
+		return nil, fmt.Errorf("failed to pack %s: %w", path, err)
+	}
+	if err = zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to pack %s: %w", path, err)
+	}
+	resp := textResponse(req, http.StatusOK, "")
+	resp.Body = io.NopCloser(buf)
+	resp.ContentLength = int64(buf.Len())
+	return resp, nil
+}
+
+func textResponse(req *http.Request, status int, body string) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,279 @@
+package tfprovider
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/matt-FFFFFF/tfpluginschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testPlatform = Platform{OS: runtime.GOOS, Arch: runtime.GOARCH}
+
+// fakeProviderZip returns a zip archive containing an empty provider "binary", enough for tfpluginschema to find it.
+func fakeProviderZip(t *testing.T, providerType, version string) []byte {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "provider.zip")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	zw := zip.NewWriter(f)
+	w, err := zw.Create(fmt.Sprintf("terraform-provider-%s_v%s", providerType, version))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("#!/bin/sh\n"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	require.NoError(t, f.Close())
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return content
+}
+
+// useTransport routes http.DefaultClient, which tfpluginschema uses, through the registry for the duration of the test.
+func useTransport(t *testing.T, r *Registry, base http.RoundTripper) {
+	t.Helper()
+	previous := http.DefaultClient.Transport
+	http.DefaultClient.Transport = r.Transport(base)
+	t.Cleanup(func() {
+		http.DefaultClient.Transport = previous
+	})
+}
+
+// newPrivateRegistry starts a TLS stand-in for a private registry that requires a bearer token.
+func newPrivateRegistry(t *testing.T, token string, providerZip []byte) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	mux.HandleFunc("/.well-known/terraform.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"providers.v1":"/api/v1/providers/"}`)
+	})
+	mux.HandleFunc("/api/v1/providers/acme/internal/versions", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"versions":[{"version":"1.0.0"},{"version":"1.2.0"}]}`)
+	})
+	mux.HandleFunc(fmt.Sprintf("/api/v1/providers/acme/internal/1.2.0/download/%s/%s", testPlatform.OS, testPlatform.Arch), func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"filename":     packageFilename(Address{Type: "internal"}, "1.2.0", testPlatform),
+			"download_url": "/files/internal.zip", // relative to the registry host
+		})
+	})
+	mux.HandleFunc("/files/internal.zip", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(providerZip)
+	})
+	return srv
+}
+
+func TestTransport_PrivateRegistryWithCredentials(t *testing.T) {
+	const token = "s3cr3t"
+	srv := newPrivateRegistry(t, token, fakeProviderZip(t, "internal", "1.2.0"))
+	host := srv.Listener.Addr().String()
+
+	registry := NewRegistry(&CLIConfig{Credentials: map[string]string{host: token}})
+	registry.Client = srv.Client()
+	useTransport(t, registry, srv.Client().Transport)
+
+	addr := Address{Hostname: host, Namespace: "acme", Type: "internal"}
+	versions, err := registry.ListVersions(context.Background(), addr)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"1.0.0", "1.2.0"}, versions)
+
+	server := tfpluginschema.NewServer(nil)
+	t.Cleanup(server.Cleanup)
+	require.NoError(t, server.Get(PluginRequest(Selection{Address: addr, Version: "1.2.0"})))
+}
+
+func TestTransport_PrivateRegistryWithoutCredentials(t *testing.T) {
+	srv := newPrivateRegistry(t, "s3cr3t", fakeProviderZip(t, "internal", "1.2.0"))
+	registry := NewRegistry(nil)
+	registry.Client = srv.Client()
+
+	_, err := registry.ListVersions(context.Background(), Address{Hostname: srv.Listener.Addr().String(), Namespace: "acme", Type: "internal"})
+	require.Error(t, err)
+}
+
+func TestTransport_RejectedCredentialsAreNotNotFound(t *testing.T) {
+	srv := newPrivateRegistry(t, "s3cr3t", fakeProviderZip(t, "internal", "1.2.0"))
+	host := srv.Listener.Addr().String()
+	registry := NewRegistry(&CLIConfig{Credentials: map[string]string{host: "wrong"}})
+	registry.Client = srv.Client()
+	useTransport(t, registry, srv.Client().Transport)
+
+	server := tfpluginschema.NewServer(nil)
+	t.Cleanup(server.Cleanup)
+	err := server.Get(PluginRequest(Selection{Address: Address{Hostname: host, Namespace: "acme", Type: "internal"}, Version: "1.2.0"}))
+	require.ErrorIs(t, err, tfpluginschema.ErrPluginApi)
+	assert.NotErrorIs(t, err, tfpluginschema.ErrPluginNotFound)
+	assert.ErrorContains(t, err, "401")
+}
+
+func TestLookupStatus(t *testing.T) {
+	assert.Equal(t, http.StatusNotFound, lookupStatus(fmt.Errorf("provider not available: %w", errPackageNotFound)))
+	assert.Equal(t, http.StatusForbidden, lookupStatus(fmt.Errorf("direct: %w", &registryError{url: "https://example.com", status: http.StatusForbidden})))
+	assert.Equal(t, http.StatusBadGateway, lookupStatus(fmt.Errorf("direct: %w", &registryError{url: "https://example.com", status: http.StatusInternalServerError})))
+	assert.Equal(t, http.StatusBadGateway, lookupStatus(errors.New("dial tcp: no such host")))
+}
+
+func TestTransport_FilesystemMirror(t *testing.T) {
+	dir := t.TempDir()
+	azapi := Address{Hostname: DefaultHostname, Namespace: "Azure", Type: "azapi"}
+	random := Address{Hostname: DefaultHostname, Namespace: "hashicorp", Type: "random"}
+
+	// Packed layout.
+	packedDir := filepath.Join(dir, azapi.Hostname, azapi.Namespace, azapi.Type)
+	require.NoError(t, os.MkdirAll(packedDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(packedDir, packageFilename(azapi, "2.5.0", testPlatform)), fakeProviderZip(t, "azapi", "2.5.0"), 0644))
+	// Unpacked layout.
+	unpackedDir := filepath.Join(dir, random.Hostname, random.Namespace, random.Type, "3.7.2", testPlatform.String())
+	require.NoError(t, os.MkdirAll(unpackedDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(unpackedDir, "terraform-provider-random_v3.7.2"), []byte("#!/bin/sh\n"), 0755))
+
+	registry := NewRegistry(&CLIConfig{Installation: []InstallationMethod{
+		{Kind: InstallationFilesystemMirror, Location: dir},
+	}})
+	// Nothing may leave the machine.
+	useTransport(t, registry, roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, fmt.Errorf("unexpected request to %s", r.URL)
+	}))
+
+	versions, err := registry.ListVersions(context.Background(), azapi)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2.5.0"}, versions)
+	selection, err := Resolve(context.Background(), registry, ResolveRequest{LocalName: "random", Version: "latest"})
+	require.NoError(t, err)
+	assert.Equal(t, "3.7.2", selection.Version)
+
+	server := tfpluginschema.NewServer(nil)
+	t.Cleanup(server.Cleanup)
+	require.NoError(t, server.Get(PluginRequest(Selection{Address: azapi, Version: "2.5.0"})))
+	require.NoError(t, server.Get(PluginRequest(*selection)))
+	require.Error(t, server.Get(PluginRequest(Selection{Address: azapi, Version: "9.9.9"})))
+}
+
+func TestTransport_NetworkMirror(t *testing.T) {
+	providerZip := fakeProviderZip(t, "azapi", "2.5.0")
+	mux := http.NewServeMux()
+	mux.HandleFunc("/mirror/registry.terraform.io/Azure/azapi/index.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"versions":{"2.4.0":{},"2.5.0":{}}}`)
+	})
+	mux.HandleFunc("/mirror/registry.terraform.io/Azure/azapi/2.5.0.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"archives":{%q:{"url":"terraform-provider-azapi_2.5.0.zip"}}}`, testPlatform.String())
+	})
+	mux.HandleFunc("/mirror/registry.terraform.io/Azure/azapi/terraform-provider-azapi_2.5.0.zip", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(providerZip)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	mirrorURL, err := url.JoinPath(srv.URL, "mirror")
+	require.NoError(t, err)
+	registry := NewRegistry(&CLIConfig{Installation: []InstallationMethod{
+		{Kind: InstallationNetworkMirror, Location: mirrorURL + "/"},
+	}})
+	useTransport(t, registry, http.DefaultTransport)
+
+	selection, err := Resolve(context.Background(), registry, ResolveRequest{LocalName: "azapi", Namespace: "Azure", Version: "~> 2.0"})
+	require.NoError(t, err)
+	assert.Equal(t, "2.5.0", selection.Version)
+
+	server := tfpluginschema.NewServer(nil)
+	t.Cleanup(server.Cleanup)
+	require.NoError(t, server.Get(PluginRequest(*selection)))
+}
+
+func TestTransport_RejectsFileURLsFromRegistries(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"download_url":"file:///etc/passwd"}`)
+	}))
+	t.Cleanup(srv.Close)
+	registry := NewRegistry(nil)
+	registry.BaseURL = srv.URL + "/v1/providers"
+
+	_, err := registry.findPackage(context.Background(), Address{Hostname: DefaultHostname, Namespace: "Azure", Type: "azapi"}, "2.5.0", testPlatform)
+	assert.ErrorContains(t, err, "is not an HTTP URL")
+}
+
+func TestTransport_ServesOnlyFilesInMirrors(t *testing.T) {
+	mirror := t.TempDir()
+	outside := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(outside, []byte("secret"), 0600))
+	registry := NewRegistry(&CLIConfig{Installation: []InstallationMethod{
+		{Kind: InstallationFilesystemMirror, Location: mirror},
+	}})
+	tr := registry.Transport(nil).(*transport)
+	// Even an issued URL is only served from a mirror.
+	tr.issued.Store(fileURL(outside), struct{}{})
+
+	req, err := http.NewRequest(http.MethodGet, fileURL(outside), nil)
+	require.NoError(t, err)
+	resp, err := tr.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	// fileURL would clean the path.
+	traversal := (&url.URL{Scheme: "file", Path: filepath.ToSlash(mirror) + "/../" + filepath.Base(filepath.Dir(outside)) + "/secret"}).String()
+	tr.issued.Store(traversal, struct{}{})
+	req, err = http.NewRequest(http.MethodGet, traversal, nil)
+	require.NoError(t, err)
+	resp, err = tr.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestTransport_PassesOtherRequestsThrough(t *testing.T) {
+	var authorization string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+	}))
+	t.Cleanup(srv.Close)
+	registry := NewRegistry(&CLIConfig{Credentials: map[string]string{srv.Listener.Addr().String(): "s3cr3t"}})
+	client := &http.Client{Transport: registry.Transport(nil)}
+
+	resp, err := client.Get(srv.URL + "/.well-known/jwks.json")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Empty(t, authorization, "credentials are only sent with provider downloads")
+
+	_, err = client.Get("file:///etc/passwd")
+	assert.Error(t, err, "file URLs are only served for issued packages")
+}
+
+func TestParsePluginAPIURL(t *testing.T) {
+	u, err := url.Parse(PluginRequest(Selection{
+		Address: Address{Hostname: "registry.example.com", Namespace: "acme", Type: "internal"},
+		Version: "1.0.0",
+	}).String())
+	require.NoError(t, err)
+	addr, version, platform, ok := parsePluginAPIURL(u)
+	require.True(t, ok)
+	assert.Equal(t, Address{Hostname: "registry.example.com", Namespace: "acme", Type: "internal"}, addr)
+	assert.Equal(t, "1.0.0", version)
+	assert.Equal(t, testPlatform, platform)
+
+	u, err = url.Parse(PluginRequest(Selection{Address: Address{Hostname: DefaultHostname, Namespace: "Azure", Type: "azapi"}, Version: "2.5.0"}).String())
+	require.NoError(t, err)
+	addr, _, _, ok = parsePluginAPIURL(u)
+	require.True(t, ok)
+	assert.Equal(t, DefaultHostname, addr.Hostname)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
@@ -0,0 +1,59 @@
+package tfprovider
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	goversion "github.com/hashicorp/go-version"
+)
+
+// LatestVersion can be used instead of a version constraint to select the newest released version.
+const LatestVersion = "latest"
+
+// LatestMatching returns the newest version in versions that satisfies every constraint.
+// Empty constraints match any version. Pre-releases are only selected when a constraint names them exactly.
+func LatestMatching(versions []string, constraints ...string) (string, error) {
+	var cs goversion.Constraints
+	for _, c := range constraints {
+		if strings.TrimSpace(c) == "" {
+			continue
+		}
+		parsed, err := goversion.NewConstraint(c)
+		if err != nil {
+			return "", fmt.Errorf("invalid version constraint %q: %w", c, err)
+		}
+		cs = append(cs, parsed...)
+	}
+	candidates := make([]*goversion.Version, 0, len(versions))
+	for _, v := range versions {
+		parsed, err := goversion.NewVersion(v)
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, parsed)
+	}
+	sort.Sort(sort.Reverse(goversion.Collection(candidates)))
+	for _, v := range candidates {
+		// go-version only lets a constraint match a pre-release when the constraint names one itself.
+		if len(cs) == 0 && v.Prerelease() != "" {
+			continue
+		}
+		if cs.Check(v) {
+			return v.Original(), nil
+		}
+	}
+	if len(cs) == 0 {
+		return "", fmt.Errorf("no released versions available")
+	}
+	return "", fmt.Errorf("no available version matches %q", cs.String())
+}
+
+// exactVersion reports whether v names a single version rather than a constraint, and returns it normalized.
+func exactVersion(v string) (string, bool) {
+	parsed, err := goversion.NewVersion(strings.TrimSpace(v))
+	if err != nil {
+		return "", false
+	}
+	return parsed.String(), true
+}
@@ -0,0 +1,56 @@
+package tfprovider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatestMatching(t *testing.T) {
+	versions := []string{"3.117.0", "4.0.0", "4.36.0", "4.37.0", "5.0.0-beta1", "not-a-version"}
+	cases := []struct {
+		desc        string
+		constraints []string
+		expected    string
+	}{
+		{desc: "no constraint", expected: "4.37.0"},
+		{desc: "pessimistic", constraints: []string{"~> 3.0"}, expected: "3.117.0"},
+		{desc: "multiple constraints", constraints: []string{">= 4.0", "< 4.37"}, expected: "4.36.0"},
+		{desc: "exact pre-release", constraints: []string{"5.0.0-beta1"}, expected: "5.0.0-beta1"},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			v, err := LatestMatching(versions, c.constraints...)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, v)
+		})
+	}
+}
+
+func TestLatestMatching_NoMatch(t *testing.T) {
+	_, err := LatestMatching([]string{"1.0.0"}, ">= 2.0")
+	require.Error(t, err)
+}
+
+func TestLatestMatching_InvalidConstraint(t *testing.T) {
+	_, err := LatestMatching([]string{"1.0.0"}, "not a constraint")
+	require.Error(t, err)
+}
+
+func TestExactVersion(t *testing.T) {
+	cases := map[string]string{
+		"2.5.0":  "2.5.0",
+		"v2.5.0": "2.5.0",
+		" 4.0 ":  "4.0.0",
+	}
+	for input, expected := range cases {
+		v, ok := exactVersion(input)
+		assert.True(t, ok, input)
+		assert.Equal(t, expected, v, input)
+	}
+	for _, input := range []string{"~> 4.0", ">= 2.0, < 3.0", "latest", "= 2.5.0"} {
+		_, ok := exactVersion(input)
+		assert.False(t, ok, input)
+	}
+}
@@ -0,0 +1,75 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/azapi"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/toolcall"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type AzAPIResourceActionQueryParam struct {
+	ResourceType string `json:"resource_type" jsonschema:"Azure resource type, for example: Microsoft.Storage/storageAccounts"`
+	ApiVersion   string `json:"api_version" jsonschema:"Azure resource api-version, for example: 2023-01-01"`
+	Action       string `json:"action,omitempty" jsonschema:"Name of the action, for example: listKeys, if not specified, the names of the actions of the resource type will be returned"`
+	Path         string `json:"path,omitempty" jsonschema:"JSON path to query the request body or response of the action, starting with request_body or response, for example: response.keys"`
+}
+
+func QueryAzAPIResourceAction(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[AzAPIResourceActionQueryParam]) (*mcp.CallToolResultFor[any], error) {
+	resourceType := params.Arguments.ResourceType
+	apiVersion := params.Arguments.ApiVersion
+	if resourceType == "" || apiVersion == "" {
+		return nil, fmt.Errorf("%w: `resource_type` and `api_version` are required parameters", toolcall.ErrInvalidArgument)
+	}
+	action := params.Arguments.Action
+	path := params.Arguments.Path
+	if action == "" {
+		if path != "" {
+			return nil, fmt.Errorf("%w: `path` requires `action`", toolcall.ErrInvalidArgument)
+		}
+		actions, err := azapi.ListResourceActions(ctx, resourceType, apiVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get actions for %s@%s: %w", resourceType, apiVersion, err)
+		}
+		return &mcp.CallToolResultFor[any]{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("[%s]", strings.Join(actions, ",")),
+				},
+			},
+		}, nil
+	}
+	description, err := azapi.GetResourceActionDescription(ctx, resourceType, apiVersion, action, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get action %s for %s@%s: %w", action, resourceType, apiVersion, err)
+	}
+	maxDepth := toolcall.LimitsFromContext(ctx).MaxDepth
+	var collapsed []string
+	if a, ok := description.(*azapi.ResourceAction); ok {
+		// The bodies are collapsed from their own root, so the collapsed paths can be queried with `path`.
+		var requestCollapsed, responseCollapsed []string
+		a.RequestBody, requestCollapsed = azapi.CollapseDescription(a.RequestBody, "request_body", maxDepth)
+		a.Response, responseCollapsed = azapi.CollapseDescription(a.Response, "response", maxDepth)
+		collapsed = append(requestCollapsed, responseCollapsed...)
+	} else {
+		description, collapsed = azapi.CollapseDescription(description, path, maxDepth)
+	}
+	payload, err := json.Marshal(description)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal action %s for %s@%s: %w", action, resourceType, apiVersion, err)
+	}
+	content := []mcp.Content{
+		&mcp.TextContent{
+			Text: string(payload),
+		},
+	}
+	if len(collapsed) > 0 {
+		content = append(content, collapsedHint(collapsed, maxDepth))
+	}
+	return &mcp.CallToolResultFor[any]{
+		Content: content,
+	}, nil
+}
@@ -0,0 +1,54 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/azapi"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/toolcall"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type AzAPIFindPropertiesParam struct {
+	ResourceType string `json:"resource_type,omitempty" jsonschema:"Azure resource type to search, for example: Microsoft.Network/virtualNetworks, its child resource types aren't searched. End it with / to search the resource types starting with it, for example: Microsoft.Network/ or Microsoft.Network/virtualNetworks/. Omit it to search all resource types, which is slow until the server has indexed them"`
+	Path         string `json:"path,omitempty" jsonschema:"Property path from the body root, for example: properties.privateEndpointNetworkPolicies or identity.type. Start it with *. to match at any depth, for example: *.privateEndpointNetworkPolicies"`
+	Value        string `json:"value,omitempty" jsonschema:"A possible value of the property, for example: UserAssigned"`
+	Description  string `json:"description,omitempty" jsonschema:"Words the property description must contain, for example: customer managed key"`
+	Cursor       string `json:"cursor,omitempty" jsonschema:"Cursor returned by the previous call when more properties are available, omit it to get the first page"`
+}
+
+func FindAzAPIProperties(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[AzAPIFindPropertiesParam]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	if args.Path == "" && args.Value == "" && args.Description == "" {
+		return nil, fmt.Errorf("%w: one of `path`, `value` or `description` is required", toolcall.ErrInvalidArgument)
+	}
+	matches, err := azapi.FindProperties(ctx, azapi.PropertyQuery{
+		ResourceType: args.ResourceType,
+		Path:         args.Path,
+		Value:        args.Value,
+		Description:  args.Description,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find properties: %w", err)
+	}
+	page, next, err := toolcall.Page(matches, args.Cursor, toolcall.LimitsFromContext(ctx).PageSize)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal properties: %w", err)
+	}
+	content := []mcp.Content{
+		&mcp.TextContent{
+			Text: string(payload),
+		},
+	}
+	if next != "" {
+		content = append(content, nextPageHint("properties", next))
+	}
+	return &mcp.CallToolResultFor[any]{
+		Content: content,
+	}, nil
+}
@@ -0,0 +1,39 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/azapi"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/toolcall"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type AzAPIResourceListQueryParam struct {
+	ResourceType string `json:"resource_type" jsonschema:"Azure resource type, for example: Microsoft.Network/virtualNetworks/subnets"`
+	ApiVersion   string `json:"api_version" jsonschema:"Azure resource api-version, for example: 2024-05-01"`
+}
+
+func QueryAzAPIResourceList(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[AzAPIResourceListQueryParam]) (*mcp.CallToolResultFor[any], error) {
+	resourceType := params.Arguments.ResourceType
+	apiVersion := params.Arguments.ApiVersion
+	if resourceType == "" || apiVersion == "" {
+		return nil, fmt.Errorf("%w: `resource_type` and `api_version` are required parameters", toolcall.ErrInvalidArgument)
+	}
+	list, err := azapi.GetResourceList(ctx, resourceType, apiVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list operation for %s@%s: %w", resourceType, apiVersion, err)
+	}
+	payload, err := json.Marshal(list)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal list operation for %s@%s: %w", resourceType, apiVersion, err)
+	}
+	return &mcp.CallToolResultFor[any]{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: string(payload),
+			},
+		},
+	}, nil
+}
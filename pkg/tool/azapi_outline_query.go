@@ -0,0 +1,46 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/azapi"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/toolcall"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// queryAzAPIOutline answers the AzAPI schema queries which ask for a `depth`, the depth is capped by the configured maximum depth.
+func queryAzAPIOutline(ctx context.Context, args AzAPIResourceSchemaQueryParam, target azapi.Target, descriptions bool) (*mcp.CallToolResultFor[any], error) {
+	depth := args.Depth
+	if maxDepth := toolcall.LimitsFromContext(ctx).MaxDepth; maxDepth > 0 && depth > maxDepth {
+		depth = maxDepth
+	}
+	var outline *azapi.OutlineProperty
+	var err error
+	if args.Response {
+		outline, err = azapi.GetResponseOutline(ctx, args.ResourceType, args.ApiVersion, responsePath(args.Path), depth)
+	} else {
+		outline, err = azapi.GetBlockOutline(ctx, target, args.Path, depth)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resource outline for %s@%s: %w", args.ResourceType, args.ApiVersion, err)
+	}
+	text, collapsed := azapi.RenderOutline(outline, descriptions)
+	content := []mcp.Content{
+		&mcp.TextContent{
+			Text: text,
+		},
+	}
+	if len(collapsed) > 0 {
+		content = append(content, collapsedHint(collapsed, depth))
+	}
+	content = appendUnknownBody(content, target)
+	if args.Response {
+		if content, err = appendResponseExports(ctx, args, content); err != nil {
+			return nil, err
+		}
+	}
+	return &mcp.CallToolResultFor[any]{
+		Content: content,
+	}, nil
+}
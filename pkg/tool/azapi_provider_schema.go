@@ -0,0 +1,65 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/azapi"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/logging"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/tfprovider"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// azapiProviderSchema gets the schema of an AzAPI block, e.g. data.azapi_resource, from a version of the provider.
+// The version can be an exact version, a constraint or latest.
+func azapiProviderSchema(ctx context.Context, version, blockType string) (*tfjson.SchemaBlock, *tfprovider.Selection, error) {
+	server, ok := ctx.Value(tfprovider.SchemaServerContextKey{}).(*tfprovider.SchemaServer)
+	if !ok {
+		return nil, nil, fmt.Errorf("failed to get schema server from context")
+	}
+	registry, ok := ctx.Value(tfprovider.ContextKey{}).(*tfprovider.Registry)
+	if !ok {
+		registry = tfprovider.NewRegistry(nil)
+	}
+	selection, err := tfprovider.Resolve(ctx, registry, tfprovider.ResolveRequest{
+		LocalName: "azapi",
+		Namespace: "Azure",
+		Version:   version,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve provider azapi: %w", err)
+	}
+	logging.FromContext(ctx).Debug("resolved provider", "provider", selection.Address.String(), "version", selection.Version, "selected_by", selection.SelectedBy)
+	if err := server.Get(ctx, *selection); err != nil {
+		return nil, nil, fmt.Errorf("failed to get provider %s: %w", selection.Address, err)
+	}
+	if blockType == "" {
+		blockType = azapi.BlockResource
+	}
+	var b []byte
+	if dataSource, ok := strings.CutPrefix(blockType, "data."); ok {
+		b, err = server.GetDataSourceSchema(ctx, *selection, dataSource)
+	} else {
+		b, err = server.GetResourceSchema(ctx, *selection, blockType)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get schema for %s from provider %s version %s: %w", blockType, selection.Address, selection.Version, err)
+	}
+	schema, err := tfprovider.ParseBlockSchema(b)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse schema for %s from provider %s version %s: %w", blockType, selection.Address, selection.Version, err)
+	}
+	return schema.Block, selection, nil
+}
+
+// appendProviderVersion tells which provider version the block attributes come from, when one was asked for.
+func appendProviderVersion(result *mcp.CallToolResultFor[any], selection *tfprovider.Selection) *mcp.CallToolResultFor[any] {
+	if selection != nil {
+		result.Content = append(result.Content, &mcp.TextContent{
+			Text: fmt.Sprintf("Attributes of provider %s version %s (version selected by %s).", selection.Address, selection.Version, selection.SelectedBy),
+		})
+	}
+	return result
+}
@@ -4,13 +4,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 
 	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/azapi"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/toolcall"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+// AzAPIResourceDescriptionQueryParam are the parameters of the former description query.
+//
+// Deprecated: QueryAzAPIDescriptionSchema takes AzAPIResourceSchemaQueryParam, the same parameters as QueryAzAPIResourceSchema.
 type AzAPIResourceDescriptionQueryParam struct {
 	ResourceType string `json:"resource_type" jsonschema:"Azure resource type, for example: Microsoft.Compute/virtualMachines, combined with api_version to identify the resource schema, like: Microsoft.Compute/virtualMachines@2024-11-01"`
 	ApiVersion   string `json:"api_version" jsonschema:"Azure resource api-version, for example: 2024-11-01, combined with resource_type to identify the resource schema, like: Microsoft.Compute/virtualMachines@2024-11-01"`
@@ -21,13 +24,35 @@ func QueryAzAPIDescriptionSchema(ctx context.Context, cc *mcp.ServerSession, par
 	resourceType := params.Arguments.ResourceType
 	apiVersion := params.Arguments.ApiVersion
 	if resourceType == "" || apiVersion == "" {
-		return nil, errors.New("`resource_type` and `api_version` are required parameters")
+		return nil, fmt.Errorf("%w: `resource_type` and `api_version` are required parameters", toolcall.ErrInvalidArgument)
+	}
+	if params.Arguments.Depth < 0 {
+		return nil, fmt.Errorf("%w: `depth` must not be negative", toolcall.ErrInvalidArgument)
+	}
+	target, selection, err := params.Arguments.target(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if params.Arguments.Depth > 0 {
+		result, err := queryAzAPIOutline(ctx, params.Arguments, target, true)
+		if err != nil {
+			return nil, err
+		}
+		return appendProviderVersion(result, selection), nil
 	}
 	path := params.Arguments.Path
-	schema, err := azapi.GetResourceSchemaDescription(resourceType, apiVersion, path)
+	var schema any
+	if params.Arguments.Response {
+		path = responsePath(path)
+		schema, err = azapi.GetResponseDescription(ctx, resourceType, apiVersion, path)
+	} else {
+		schema, err = azapi.GetBlockDescription(ctx, target, path)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get resource schema for %s@%s: %w", resourceType, apiVersion, err)
 	}
+	maxDepth := toolcall.LimitsFromContext(ctx).MaxDepth
+	schema, collapsed := azapi.CollapseDescription(schema, path, maxDepth)
 	payload, err := json.Marshal(schema)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal resource schema for %s@%s: %w", resourceType, apiVersion, err)
@@ -36,11 +61,21 @@ func QueryAzAPIDescriptionSchema(ctx context.Context, cc *mcp.ServerSession, par
 	if err = json.Compact(compressed, payload); err != nil {
 		return nil, fmt.Errorf("failed to compact resource schema for %s@%s: %w", resourceType, apiVersion, err)
 	}
-	return &mcp.CallToolResultFor[any]{
-		Content: []mcp.Content{
-			&mcp.TextContent{
-				Text: compressed.String(),
-			},
+	content := []mcp.Content{
+		&mcp.TextContent{
+			Text: compressed.String(),
 		},
-	}, nil
+	}
+	if len(collapsed) > 0 {
+		content = append(content, collapsedHint(collapsed, maxDepth))
+	}
+	content = appendUnknownBody(content, target)
+	if params.Arguments.Response {
+		if content, err = appendResponseExports(ctx, params.Arguments, content); err != nil {
+			return nil, err
+		}
+	}
+	return appendProviderVersion(&mcp.CallToolResultFor[any]{
+		Content: content,
+	}, selection), nil
 }
@@ -2,35 +2,115 @@ package tool
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/azapi"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/tfprovider"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/toolcall"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zclconf/go-cty/cty"
 )
 
 type AzAPIResourceSchemaQueryParam struct {
-	ResourceType string `json:"resource_type" jsonschema:"Azure resource type, for example: Microsoft.Compute/virtualMachines, combined with api_version to identify the resource schema, like: Microsoft.Compute/virtualMachines@2024-11-01"`
-	ApiVersion   string `json:"api_version" jsonschema:"Azure resource api-version, for example: 2024-11-01, combined with resource_type to identify the resource schema, like: Microsoft.Compute/virtualMachines@2024-11-01"`
-	Path         string `json:"path,omitempty" jsonschema:"JSON path to query the resource schema, for example: body.properties.osProfile.secrets.sourceVault.id, if not specified, the whole resource schema will be returned"`
+	ResourceType         string `json:"resource_type" jsonschema:"Azure resource type, for example: Microsoft.Compute/virtualMachines, combined with api_version to identify the resource schema, like: Microsoft.Compute/virtualMachines@2024-11-01"`
+	ApiVersion           string `json:"api_version" jsonschema:"Azure resource api-version, for example: 2024-11-01, combined with resource_type to identify the resource schema, like: Microsoft.Compute/virtualMachines@2024-11-01"`
+	Path                 string `json:"path,omitempty" jsonschema:"JSON path to query the resource schema, for example: body.properties.osProfile.secrets.sourceVault.id, if not specified, the whole resource schema will be returned. Select a variant of a discriminated object with name[discriminator=value], for example: body.properties[type=AzureBlobStorage].typeProperties"`
+	Depth                int    `json:"depth,omitempty" jsonschema:"Return an outline of the property names, types and flags down to this many levels instead of the whole schema, deeper objects are summarized, for example 2. Explore large resources top-down by querying the summarized paths next"`
+	Response             bool   `json:"response,omitempty" jsonschema:"Query the GET response body instead, which is the azapi_resource output attribute, it has the ReadOnly properties and suggests response_export_values. Paths start with output, for example: output.properties.provisioningState"`
+	BlockType            string `json:"block_type,omitempty" jsonschema:"AzAPI block the schema is for, can be azapi_resource, azapi_update_resource, azapi_data_plane_resource, data.azapi_resource or azapi_resource_action, defaults to azapi_resource"`
+	Action               string `json:"action,omitempty" jsonschema:"Name of the action when block_type is azapi_resource_action, for example: listKeys, its request body is the body attribute and its response the output attribute"`
+	AzAPIProviderVersion string `json:"azapi_provider_version,omitempty" jsonschema:"Version of the AzAPI provider the module uses, the attributes other than body and output are taken from it: an exact version, e.g. 2.5.0, a version constraint, e.g. ~> 2.0, or latest. Defaults to the embedded schema"`
 }
 
 func QueryAzAPIResourceSchema(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[AzAPIResourceSchemaQueryParam]) (*mcp.CallToolResultFor[any], error) {
 	resourceType := params.Arguments.ResourceType
 	apiVersion := params.Arguments.ApiVersion
 	if resourceType == "" || apiVersion == "" {
-		return nil, errors.New("`resource_type` and `api_version` are required parameters")
+		return nil, fmt.Errorf("%w: `resource_type` and `api_version` are required parameters", toolcall.ErrInvalidArgument)
+	}
+	if params.Arguments.Depth < 0 {
+		return nil, fmt.Errorf("%w: `depth` must not be negative", toolcall.ErrInvalidArgument)
+	}
+	target, selection, err := params.Arguments.target(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if params.Arguments.Depth > 0 {
+		result, err := queryAzAPIOutline(ctx, params.Arguments, target, false)
+		if err != nil {
+			return nil, err
+		}
+		return appendProviderVersion(result, selection), nil
 	}
 	path := params.Arguments.Path
-	schema, err := azapi.GetResourceSchema(resourceType, apiVersion, path)
+	var t cty.Type
+	if params.Arguments.Response {
+		path = responsePath(path)
+		t, err = azapi.GetResponseType(ctx, resourceType, apiVersion, path)
+	} else {
+		t, err = azapi.GetBlockType(ctx, target, path)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get resource schema for %s@%s: %w", resourceType, apiVersion, err)
 	}
-	return &mcp.CallToolResultFor[any]{
-		Content: []mcp.Content{
-			&mcp.TextContent{
-				Text: schema,
-			},
+	maxDepth := toolcall.LimitsFromContext(ctx).MaxDepth
+	schema, collapsed := azapi.RenderType(t, path, maxDepth)
+	content := []mcp.Content{
+		&mcp.TextContent{
+			Text: schema,
 		},
-	}, nil
+	}
+	if len(collapsed) > 0 {
+		content = append(content, collapsedHint(collapsed, maxDepth))
+	}
+	content = appendUnknownBody(content, target)
+	if params.Arguments.Response {
+		if content, err = appendResponseExports(ctx, params.Arguments, content); err != nil {
+			return nil, err
+		}
+	}
+	return appendProviderVersion(&mcp.CallToolResultFor[any]{
+		Content: content,
+	}, selection), nil
+}
+
+// appendUnknownBody tells the client when the body of the target isn't in the AzAPI types, the body is then left dynamic.
+func appendUnknownBody(content []mcp.Content, target azapi.Target) []mcp.Content {
+	if !azapi.UnknownBody(target) {
+		return content
+	}
+	return append(content, &mcp.TextContent{
+		Text: fmt.Sprintf("The body of %s %s is unknown, the AzAPI types don't have this resource type, so any value is accepted. Check its data plane API reference for the properties.", target.Block, target.ResourceType),
+	})
+}
+
+// target returns the AzAPI block queried by the arguments, with the provider schema of the asked AzAPI provider version.
+// The selected provider version is returned when there is one.
+func (args AzAPIResourceSchemaQueryParam) target(ctx context.Context) (azapi.Target, *tfprovider.Selection, error) {
+	if args.BlockType != "" && !slices.Contains(azapi.BlockTypes, args.BlockType) {
+		return azapi.Target{}, nil, fmt.Errorf("%w: `block_type` must be one of %s", toolcall.ErrInvalidArgument, strings.Join(azapi.BlockTypes, ", "))
+	}
+	if (args.BlockType == azapi.BlockResourceAction) != (args.Action != "") {
+		return azapi.Target{}, nil, fmt.Errorf("%w: `action` is required for and only supported by the %s block type", toolcall.ErrInvalidArgument, azapi.BlockResourceAction)
+	}
+	if args.Response && (args.BlockType != "" || args.AzAPIProviderVersion != "") {
+		return azapi.Target{}, nil, fmt.Errorf("%w: `response` queries the GET response and can't be combined with `block_type` or `azapi_provider_version`", toolcall.ErrInvalidArgument)
+	}
+	target := azapi.Target{
+		Block:        args.BlockType,
+		ResourceType: args.ResourceType,
+		ApiVersion:   args.ApiVersion,
+		Action:       args.Action,
+	}
+	if args.AzAPIProviderVersion == "" {
+		return target, nil, nil
+	}
+	schema, selection, err := azapiProviderSchema(ctx, args.AzAPIProviderVersion, args.BlockType)
+	if err != nil {
+		return azapi.Target{}, nil, err
+	}
+	target.Schema = schema
+	return target, selection, nil
 }
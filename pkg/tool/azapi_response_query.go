@@ -0,0 +1,32 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/azapi"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// responsePath defaults the path of response queries to the whole output.
+func responsePath(path string) string {
+	if path == "" {
+		return "output"
+	}
+	return path
+}
+
+// appendResponseExports appends the suggested response_export_values when the whole response is queried.
+func appendResponseExports(ctx context.Context, args AzAPIResourceSchemaQueryParam, content []mcp.Content) ([]mcp.Content, error) {
+	if responsePath(args.Path) != "output" {
+		return content, nil
+	}
+	exports, err := azapi.GetResponseExports(ctx, args.ResourceType, args.ApiVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest response_export_values for %s@%s: %w", args.ResourceType, args.ApiVersion, err)
+	}
+	if text := azapi.RenderResponseExports(exports); text != "" {
+		content = append(content, &mcp.TextContent{Text: text})
+	}
+	return content, nil
+}
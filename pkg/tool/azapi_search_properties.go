@@ -0,0 +1,47 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/azapi"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/toolcall"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type AzAPISearchPropertiesParam struct {
+	Query        string `json:"query" jsonschema:"Words to search the property descriptions, names and possible values for, for example: zone redundant"`
+	ResourceType string `json:"resource_type,omitempty" jsonschema:"Azure resource type to search, for example: Microsoft.Network/virtualNetworks, its child resource types aren't searched. End it with / to search the resource types starting with it, for example: Microsoft.Network/ or Microsoft.Network/virtualNetworks/. Omit it to search all resource types, which is slow until the server has indexed them"`
+	Cursor       string `json:"cursor,omitempty" jsonschema:"Cursor returned by the previous call when more results are available, omit it to get the first page"`
+}
+
+func SearchAzAPIProperties(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[AzAPISearchPropertiesParam]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	if args.Query == "" {
+		return nil, fmt.Errorf("%w: `query` is a required parameter", toolcall.ErrInvalidArgument)
+	}
+	results, err := azapi.SearchProperties(ctx, args.ResourceType, args.Query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search properties: %w", err)
+	}
+	page, next, err := toolcall.Page(results, args.Cursor, toolcall.LimitsFromContext(ctx).PageSize)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal search results: %w", err)
+	}
+	content := []mcp.Content{
+		&mcp.TextContent{
+			Text: string(payload),
+		},
+	}
+	if next != "" {
+		content = append(content, nextPageHint("results", next))
+	}
+	return &mcp.CallToolResultFor[any]{
+		Content: content,
+	}, nil
+}
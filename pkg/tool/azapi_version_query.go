@@ -2,33 +2,42 @@ package tool
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/azapi"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/toolcall"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 type AzAPIVersionQueryParam struct {
 	ResourceType string `json:"resource_type" jsonschema:"Azure resource type, for example: Microsoft.Compute/virtualMachines"`
+	Cursor       string `json:"cursor,omitempty" jsonschema:"Cursor returned by the previous call when more API versions are available, omit it to get the first page"`
 }
 
 func QueryAzAPIVersions(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[AzAPIVersionQueryParam]) (*mcp.CallToolResultFor[any], error) {
 	resourceType := params.Arguments.ResourceType
 	if resourceType == "" {
-		return nil, errors.New("`resource_type` are required parameters")
+		return nil, fmt.Errorf("%w: `resource_type` are required parameters", toolcall.ErrInvalidArgument)
 	}
 
 	versions, err := azapi.GetApiVersions(resourceType)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get versions for %s: %w", resourceType, err)
 	}
-	return &mcp.CallToolResultFor[any]{
-		Content: []mcp.Content{
-			&mcp.TextContent{
-				Text: fmt.Sprintf("[%s]", strings.Join(versions, ",")),
-			},
+	page, next, err := toolcall.Page(versions, params.Arguments.Cursor, toolcall.LimitsFromContext(ctx).PageSize)
+	if err != nil {
+		return nil, err
+	}
+	content := []mcp.Content{
+		&mcp.TextContent{
+			Text: fmt.Sprintf("[%s]", strings.Join(page, ",")),
 		},
+	}
+	if next != "" {
+		content = append(content, nextPageHint("API versions", next))
+	}
+	return &mcp.CallToolResultFor[any]{
+		Content: content,
 	}, nil
 }
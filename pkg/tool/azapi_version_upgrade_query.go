@@ -0,0 +1,41 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/azapi"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/toolcall"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type AzAPIVersionUpgradeQueryParam struct {
+	Hcl        string `json:"hcl" jsonschema:"HCL of an azapi_resource block with a literal type attribute, for example: resource \"azapi_resource\" \"this\" { type = \"Microsoft.Storage/storageAccounts@2021-01-01\" ... }"`
+	ApiVersion string `json:"api_version" jsonschema:"The api-version to upgrade the block to, for example: 2023-01-01"`
+}
+
+func QueryAzAPIVersionUpgrade(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[AzAPIVersionUpgradeQueryParam]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	if args.Hcl == "" || args.ApiVersion == "" {
+		return nil, fmt.Errorf("%w: `hcl` and `api_version` are required parameters", toolcall.ErrInvalidArgument)
+	}
+	upgrade, err := azapi.CheckApiVersionUpgrade(ctx, args.Hcl, args.ApiVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check upgrade to api-version %s: %w", args.ApiVersion, err)
+	}
+	payload, err := json.Marshal(upgrade)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal upgrade to api-version %s: %w", args.ApiVersion, err)
+	}
+	return &mcp.CallToolResultFor[any]{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: string(payload),
+			},
+			&mcp.TextContent{
+				Text: "Suggested block:\n" + upgrade.Block,
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,29 @@
+package tool
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxCollapsedPaths is the number of collapsed paths listed in the hint, the rest is counted.
+const maxCollapsedPaths = 10
+
+// collapsedHint tells the client which objects were collapsed to `{...}` and how to see them.
+func collapsedHint(collapsed []string, maxDepth int) mcp.Content {
+	listed := collapsed
+	if len(listed) > maxCollapsedPaths {
+		listed = listed[:maxCollapsedPaths]
+	}
+	text := fmt.Sprintf("Objects nested more than %d levels deep are shown as {...}, query them with `path`, e.g. %s", maxDepth, strings.Join(listed, ", "))
+	if more := len(collapsed) - len(listed); more > 0 {
+		text += fmt.Sprintf(" and %d more", more)
+	}
+	return &mcp.TextContent{Text: text + "."}
+}
+
+// nextPageHint tells the client how to get the next page of a list.
+func nextPageHint(what, cursor string) mcp.Content {
+	return &mcp.TextContent{Text: fmt.Sprintf("More %s are available, call again with cursor %q.", what, cursor)}
+}
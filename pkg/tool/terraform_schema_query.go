@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/matt-FFFFFF/tfpluginschema"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/logging"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/tfprovider"
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/toolcall"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -18,9 +20,11 @@ const (
 type FineGrainedSchemaQueryParam struct {
 	BlockType         string `json:"block_type" jsonschema:"Terraform block type, possible values: provider, resource, data, ephemeral"`
 	ProviderName      string `json:"provider_name" jsonschema:"The name of the provider: azapi, azurerm, etc. This is the first segment of the block label, e.g. for azurerm_virtual_machine it's azurerm."`
-	ProviderNamespace string `json:"provider_namespace" jsonschema:"The namespace of the provider, e.g. Azure, hashicorp, etc. Look this up in the terraform.required_providers block."`
-	ProviderVersion   string `json:"provider_version" jsonschema:"The version of the provider, e.g. 2.5.0. MUST be obtained by running 'terraform providers' command."`
+	ProviderNamespace string `json:"provider_namespace,omitempty" jsonschema:"The namespace of the provider, e.g. Azure, hashicorp, etc. Look this up in the terraform.required_providers block. Can be omitted when working_dir or lock_file is supplied."`
+	ProviderVersion   string `json:"provider_version,omitempty" jsonschema:"The version of the provider: an exact version, e.g. 2.5.0, a version constraint, e.g. ~> 4.0, or latest. Constraints are resolved against the registry. Can be omitted when working_dir or lock_file is supplied, the version is then resolved from the lock file or the required_providers constraints."`
 	BlockLabel        string `json:"block_label" jsonschema:"The first label of the block, e.g. azurerm_virtual_machine. Not required for provider block type."`
+	WorkingDir        string `json:"working_dir,omitempty" jsonschema:"Absolute path of the Terraform working directory. The provider namespace and version are resolved from its .terraform.lock.hcl and required_providers block. Servers shared over HTTP only accept the directories they are configured to allow, supply lock_file otherwise."`
+	LockFile          string `json:"lock_file,omitempty" jsonschema:"Content of a .terraform.lock.hcl file, used to resolve the provider namespace and version when working_dir is not accessible to the server."`
 }
 
 var validCategories = map[string]struct{}{
@@ -32,34 +36,52 @@ var validCategories = map[string]struct{}{
 
 func QueryResourceSchema(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[FineGrainedSchemaQueryParam]) (*mcp.CallToolResultFor[any], error) {
 	if _, ok := validCategories[params.Arguments.BlockType]; !ok {
-		return nil, fmt.Errorf("invalid category: %s", params.Arguments.BlockType)
+		return nil, fmt.Errorf("%w: invalid category: %s", toolcall.ErrInvalidArgument, params.Arguments.BlockType)
 	}
 
-	server, ok := ctx.Value(tfpluginschema.ContextKey{}).(*tfpluginschema.Server)
+	server, ok := ctx.Value(tfprovider.SchemaServerContextKey{}).(*tfprovider.SchemaServer)
 	if !ok {
-		return nil, fmt.Errorf("failed to get tfpluginschema server from context")
+		return nil, fmt.Errorf("failed to get schema server from context")
 	}
 
-	req := tfpluginschema.Request{
-		Namespace: params.Arguments.ProviderNamespace,
-		Version:   params.Arguments.ProviderVersion,
-		Name:      params.Arguments.ProviderName,
+	if dir := params.Arguments.WorkingDir; dir != "" {
+		// The server may be shared, its clients must not read any directory they like.
+		workingDirs, _ := ctx.Value(tfprovider.WorkingDirsContextKey{}).(tfprovider.WorkingDirs)
+		if !workingDirs.Allows(dir) {
+			return nil, fmt.Errorf("%w: `working_dir` %s is not allowed by this server, supply the `lock_file` content instead", toolcall.ErrInvalidArgument, dir)
+		}
 	}
-	if err := server.Get(req); err != nil {
-		return nil, fmt.Errorf("failed to get provider %s: %w", req.Name, err)
+
+	registry, ok := ctx.Value(tfprovider.ContextKey{}).(*tfprovider.Registry)
+	if !ok {
+		registry = tfprovider.NewRegistry(nil)
+	}
+	selection, err := tfprovider.Resolve(ctx, registry, tfprovider.ResolveRequest{
+		LocalName:  params.Arguments.ProviderName,
+		Namespace:  params.Arguments.ProviderNamespace,
+		Version:    params.Arguments.ProviderVersion,
+		WorkingDir: params.Arguments.WorkingDir,
+		LockFile:   params.Arguments.LockFile,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve provider %s: %w", params.Arguments.ProviderName, err)
+	}
+	logging.FromContext(ctx).Debug("resolved provider", "provider", selection.Address.String(), "version", selection.Version, "selected_by", selection.SelectedBy)
+
+	if err := server.Get(ctx, *selection); err != nil {
+		return nil, fmt.Errorf("failed to get provider %s: %w", selection.Address, err)
 	}
 
-	var err error
 	var returnData []byte
 	switch params.Arguments.BlockType {
 	case blockTypeResource:
-		returnData, err = server.GetResourceSchema(req, params.Arguments.BlockLabel)
+		returnData, err = server.GetResourceSchema(ctx, *selection, params.Arguments.BlockLabel)
 	case blockTypeData:
-		returnData, err = server.GetDataSourceSchema(req, params.Arguments.BlockLabel)
+		returnData, err = server.GetDataSourceSchema(ctx, *selection, params.Arguments.BlockLabel)
 	case blockTypeEphemeral:
-		returnData, err = server.GetEphemeralResourceSchema(req, params.Arguments.BlockLabel)
+		returnData, err = server.GetEphemeralResourceSchema(ctx, *selection, params.Arguments.BlockLabel)
 	case blockTypeProvider:
-		returnData, err = server.GetProviderSchema(req)
+		returnData, err = server.GetProviderSchema(ctx, *selection)
 	}
 
 	if err != nil || len(returnData) == 0 {
@@ -76,6 +98,14 @@ func QueryResourceSchema(ctx context.Context, cc *mcp.ServerSession, params *mcp
 					},
 				},
 			},
+			&mcp.TextContent{
+				Text: fmt.Sprintf("Schema of provider %s version %s (version selected by %s).", selection.Address, selection.Version, selection.SelectedBy),
+				Annotations: &mcp.Annotations{
+					Audience: []mcp.Role{
+						"assistant",
+					},
+				},
+			},
 		},
 	}, nil
 }
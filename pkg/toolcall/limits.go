@@ -0,0 +1,91 @@
+package toolcall
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// The limits the server applies unless configured otherwise.
+const (
+	DefaultMaxBytes = 100_000
+	DefaultMaxDepth = 10
+	DefaultPageSize = 50
+)
+
+// Limits bound the size of tool results, a zero value means no limit.
+type Limits struct {
+	// MaxBytes truncates the text content of results.
+	MaxBytes int
+	// MaxDepth collapses objects nested deeper than this many levels, in the results of tools rendering nested schemas.
+	MaxDepth int
+	// PageSize is the number of items tools returning long lists return per call, see Page.
+	PageSize int
+}
+
+// LimitsContextKey is a type used to store the Limits in the context of tool calls.
+type LimitsContextKey struct{}
+
+// LimitsFromContext returns the limits ApplyLimits stored in ctx, none outside of a tool call.
+func LimitsFromContext(ctx context.Context) Limits {
+	limits, _ := ctx.Value(LimitsContextKey{}).(Limits)
+	return limits
+}
+
+// ApplyLimits makes limits available to the tools and truncates the text content of their results to limits.MaxBytes in total.
+// A note telling the client how much was cut is appended, so it can narrow the query.
+func ApplyLimits(limits Limits) Middleware {
+	maxBytes := limits.MaxBytes
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*mcp.CallToolResult, error) {
+			res, err := next(context.WithValue(ctx, LimitsContextKey{}, limits), call)
+			if err != nil || res == nil || maxBytes <= 0 {
+				return res, err
+			}
+			total := 0
+			for _, c := range res.Content {
+				if text, ok := c.(*mcp.TextContent); ok {
+					total += len(text.Text)
+				}
+			}
+			if total <= maxBytes {
+				return res, nil
+			}
+			remaining := maxBytes
+			content := make([]mcp.Content, 0, len(res.Content)+1)
+			for _, c := range res.Content {
+				text, ok := c.(*mcp.TextContent)
+				if !ok {
+					content = append(content, c)
+					continue
+				}
+				if remaining == 0 {
+					continue
+				}
+				truncated := *text
+				truncated.Text = truncate(text.Text, remaining)
+				remaining -= len(truncated.Text)
+				content = append(content, &truncated)
+			}
+			content = append(content, &mcp.TextContent{
+				Text: fmt.Sprintf("Output truncated to %d of %d bytes, narrow the query (e.g. with a path) to see the rest.", maxBytes-remaining, total),
+			})
+			limited := *res
+			limited.Content = content
+			return &limited, nil
+		}
+	}
+}
+
+// truncate cuts s to at most n bytes without splitting a UTF-8 sequence.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
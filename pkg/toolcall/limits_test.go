@@ -0,0 +1,45 @@
+package toolcall
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyLimits_Truncates(t *testing.T) {
+	h := Wrap("echo", echo, ApplyLimits(Limits{MaxBytes: 10}))
+	res, err := h(context.Background(), nil, &mcp.CallToolParamsFor[echoParam]{Name: "echo", Arguments: echoParam{Text: strings.Repeat("ab", 10)}})
+	require.NoError(t, err)
+	require.Len(t, res.Content, 2)
+	assert.Equal(t, "ababababab", res.Content[0].(*mcp.TextContent).Text)
+	assert.Contains(t, res.Content[1].(*mcp.TextContent).Text, "truncated to 10 of 20 bytes")
+}
+
+func TestApplyLimits_KeepsSmallResults(t *testing.T) {
+	h := Wrap("echo", echo, ApplyLimits(Limits{MaxBytes: 10}))
+	res, err := h(context.Background(), nil, &mcp.CallToolParamsFor[echoParam]{Name: "echo", Arguments: echoParam{Text: "short"}})
+	require.NoError(t, err)
+	require.Len(t, res.Content, 1)
+	assert.Equal(t, "short", res.Content[0].(*mcp.TextContent).Text)
+}
+
+func TestApplyLimits_KeepsRunesIntact(t *testing.T) {
+	assert.Equal(t, "a", truncate("aé", 2))
+	assert.Equal(t, "aé", truncate("aé", 3))
+}
+
+func TestApplyLimits_ExposesLimits(t *testing.T) {
+	var seen Limits
+	h := ApplyLimits(Limits{MaxDepth: 3, PageSize: 20})(func(ctx context.Context, call *Call) (*mcp.CallToolResult, error) {
+		seen = LimitsFromContext(ctx)
+		return &mcp.CallToolResult{}, nil
+	})
+	_, err := h(context.Background(), &Call{Name: "echo"})
+	require.NoError(t, err)
+	assert.Equal(t, Limits{MaxDepth: 3, PageSize: 20}, seen)
+	assert.Equal(t, Limits{}, LimitsFromContext(context.Background()))
+}
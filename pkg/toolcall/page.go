@@ -0,0 +1,32 @@
+package toolcall
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+)
+
+// Page returns the page of items starting at cursor, an empty cursor being the first page,
+// and the cursor of the next page, empty on the last one. A size of 0 returns all remaining items.
+// Cursors are opaque to clients, they pass back the one of the previous result.
+func Page[T any](items []T, cursor string, size int) ([]T, string, error) {
+	offset := 0
+	if cursor != "" {
+		decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err == nil {
+			offset, err = strconv.Atoi(string(decoded))
+		}
+		if err != nil || offset < 0 || offset > len(items) {
+			return nil, "", fmt.Errorf("%w: invalid cursor %q", ErrInvalidArgument, cursor)
+		}
+	}
+	end := len(items)
+	if size > 0 && offset+size < end {
+		end = offset + size
+	}
+	next := ""
+	if end < len(items) {
+		next = base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(end)))
+	}
+	return items[offset:end], next, nil
+}
@@ -0,0 +1,39 @@
+package toolcall
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPage_WalksAllItems(t *testing.T) {
+	items := []string{"a", "b", "c", "d", "e"}
+	var seen []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 3)
+		page, next, err := Page(items, cursor, 2)
+		require.NoError(t, err)
+		seen = append(seen, page...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	assert.Equal(t, items, seen)
+}
+
+func TestPage_Unlimited(t *testing.T) {
+	page, next, err := Page([]int{1, 2, 3}, "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, page)
+	assert.Empty(t, next)
+}
+
+func TestPage_InvalidCursor(t *testing.T) {
+	for _, cursor := range []string{"not base64!", "OTk", "LTE"} {
+		_, _, err := Page([]int{1, 2, 3}, cursor, 2)
+		require.ErrorIs(t, err, ErrInvalidArgument, cursor)
+	}
+}
@@ -0,0 +1,60 @@
+package toolcall
+
+import (
+	"context"
+	"errors"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ErrInvalidArgument is wrapped by the errors tools return for missing or malformed arguments.
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// Call describes a tool invocation to a Middleware.
+type Call struct {
+	Name    string
+	Session *mcp.ServerSession
+	// Arguments holds the decoded arguments, e.g. a tool.AzAPIResourceSchemaQueryParam.
+	Arguments any
+}
+
+// Handler is a tool handler with its argument and result types erased.
+type Handler func(ctx context.Context, call *Call) (*mcp.CallToolResult, error)
+
+// Middleware wraps tool handlers, e.g. to record metrics for every call.
+type Middleware func(next Handler) Handler
+
+// Wrap applies middlewares to a typed tool handler, the first middleware is the outermost.
+func Wrap[In, Out any](name string, h mcp.ToolHandlerFor[In, Out], middlewares ...Middleware) mcp.ToolHandlerFor[In, Out] {
+	if len(middlewares) == 0 {
+		return h
+	}
+	return func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[In]) (*mcp.CallToolResultFor[Out], error) {
+		var structured Out
+		var handler Handler = func(ctx context.Context, call *Call) (*mcp.CallToolResult, error) {
+			res, err := h(ctx, ss, params)
+			if err != nil || res == nil {
+				return nil, err
+			}
+			structured = res.StructuredContent
+			return &mcp.CallToolResult{
+				Meta:    res.Meta,
+				Content: res.Content,
+				IsError: res.IsError,
+			}, nil
+		}
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			handler = middlewares[i](handler)
+		}
+		res, err := handler(ctx, &Call{Name: name, Session: ss, Arguments: params.Arguments})
+		if err != nil || res == nil {
+			return nil, err
+		}
+		return &mcp.CallToolResultFor[Out]{
+			Meta:              res.Meta,
+			Content:           res.Content,
+			IsError:           res.IsError,
+			StructuredContent: structured,
+		}, nil
+	}
+}
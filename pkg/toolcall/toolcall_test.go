@@ -0,0 +1,43 @@
+package toolcall
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type echoParam struct {
+	Text string `json:"text"`
+}
+
+func echo(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[echoParam]) (*mcp.CallToolResultFor[any], error) {
+	return &mcp.CallToolResultFor[any]{
+		Content: []mcp.Content{&mcp.TextContent{Text: params.Arguments.Text}},
+	}, nil
+}
+
+func TestWrap_MiddlewareOrder(t *testing.T) {
+	var trace []string
+	record := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, call *Call) (*mcp.CallToolResult, error) {
+				trace = append(trace, name+":"+call.Name+":"+call.Arguments.(echoParam).Text)
+				res, err := next(ctx, call)
+				res.Content = append(res.Content, &mcp.TextContent{Text: name})
+				return res, err
+			}
+		}
+	}
+	h := Wrap("echo", echo, record("outer"), record("inner"))
+	res, err := h(context.Background(), nil, &mcp.CallToolParamsFor[echoParam]{Name: "echo", Arguments: echoParam{Text: "hi"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"outer:echo:hi", "inner:echo:hi"}, trace)
+	var texts []string
+	for _, c := range res.Content {
+		texts = append(texts, c.(*mcp.TextContent).Text)
+	}
+	assert.Equal(t, []string{"hi", "inner", "outer"}, texts, "middlewares can amend the result")
+}
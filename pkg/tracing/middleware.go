@@ -0,0 +1,28 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/toolcall"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ToolMiddleware wraps every tool call in a span, spans started by the tool are nested under it.
+func ToolMiddleware(next toolcall.Handler) toolcall.Handler {
+	return func(ctx context.Context, call *toolcall.Call) (*mcp.CallToolResult, error) {
+		attrs := []attribute.KeyValue{attribute.String("mcp.tool.name", call.Name)}
+		if call.Session != nil && call.Session.ID() != "" {
+			attrs = append(attrs, attribute.String("mcp.session.id", call.Session.ID()))
+		}
+		ctx, span := Start(ctx, "tool "+call.Name, attrs...)
+		res, err := next(ctx, call)
+		if err == nil && res != nil && res.IsError {
+			End(span, errors.New("tool returned an error result"))
+			return res, err
+		}
+		End(span, err)
+		return res, err
+	}
+}
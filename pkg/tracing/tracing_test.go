@@ -0,0 +1,142 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/matt-FFFFFF/terraform-mcp-eva/pkg/toolcall"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// collector is a stand-in for an OTLP/HTTP collector that keeps the spans it receives.
+type collector struct {
+	mu    sync.Mutex
+	paths []string
+	spans []*tracepb.Span
+}
+
+func newCollector(t *testing.T) (*collector, *httptest.Server) {
+	t.Helper()
+	c := &collector{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req := &coltracepb.ExportTraceServiceRequest{}
+		if err = proto.Unmarshal(body, req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		c.mu.Lock()
+		c.paths = append(c.paths, r.URL.Path)
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				c.spans = append(c.spans, ss.Spans...)
+			}
+		}
+		c.mu.Unlock()
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		out, _ := proto.Marshal(&coltracepb.ExportTraceServiceResponse{})
+		_, _ = w.Write(out)
+	}))
+	t.Cleanup(srv.Close)
+	return c, srv
+}
+
+func (c *collector) span(name string) *tracepb.Span {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, s := range c.spans {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// setup enables tracing for the test and restores the global tracer provider afterwards.
+func setup(t *testing.T, endpoint string) func(context.Context) error {
+	t.Helper()
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+	})
+	shutdown, err := Setup(context.Background(), Options{Endpoint: endpoint, ServiceName: "test", ServiceVersion: "0.0.0"})
+	require.NoError(t, err)
+	return shutdown
+}
+
+func TestToolMiddleware_ExportsNestedSpans(t *testing.T) {
+	c, srv := newCollector(t)
+	shutdown := setup(t, srv.URL)
+
+	handler := ToolMiddleware(func(ctx context.Context, call *toolcall.Call) (*mcp.CallToolResult, error) {
+		_, span := Start(ctx, "azapi.load_type")
+		End(span, nil)
+		return &mcp.CallToolResult{}, nil
+	})
+	_, err := handler(context.Background(), &toolcall.Call{Name: "azapi_resource_schema_query"})
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+
+	assert.Equal(t, []string{"/v1/traces"}, c.paths)
+	tool := c.span("tool azapi_resource_schema_query")
+	require.NotNil(t, tool)
+	nested := c.span("azapi.load_type")
+	require.NotNil(t, nested)
+	assert.Equal(t, tool.TraceId, nested.TraceId)
+	assert.Equal(t, tool.SpanId, nested.ParentSpanId)
+	assert.Equal(t, tracepb.Status_STATUS_CODE_UNSET, tool.Status.GetCode())
+}
+
+func TestToolMiddleware_RecordsErrors(t *testing.T) {
+	c, srv := newCollector(t)
+	shutdown := setup(t, srv.URL+"/custom/traces")
+
+	failing := ToolMiddleware(func(ctx context.Context, call *toolcall.Call) (*mcp.CallToolResult, error) {
+		return nil, errors.New("boom")
+	})
+	_, err := failing(context.Background(), &toolcall.Call{Name: "failing"})
+	require.Error(t, err)
+	errorResult := ToolMiddleware(func(ctx context.Context, call *toolcall.Call) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{IsError: true}, nil
+	})
+	_, err = errorResult(context.Background(), &toolcall.Call{Name: "error_result"})
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+
+	assert.Equal(t, []string{"/custom/traces"}, c.paths)
+	span := c.span("tool failing")
+	require.NotNil(t, span)
+	assert.Equal(t, tracepb.Status_STATUS_CODE_ERROR, span.Status.GetCode())
+	assert.Equal(t, "boom", span.Status.GetMessage())
+	span = c.span("tool error_result")
+	require.NotNil(t, span)
+	assert.Equal(t, tracepb.Status_STATUS_CODE_ERROR, span.Status.GetCode())
+}
+
+func TestSetup_Disabled(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	shutdown, err := Setup(context.Background(), Options{})
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+	assert.Equal(t, previous, otel.GetTracerProvider())
+}
+
+func TestSetup_InvalidEndpoint(t *testing.T) {
+	_, err := Setup(context.Background(), Options{Endpoint: "localhost:4318"})
+	require.Error(t, err)
+}